@@ -0,0 +1,315 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ClaimsAttribute is the key for the generic Claims stored in the request
+	ClaimsAttribute = "AuthClaims"
+)
+
+// Claims is the token type agnostic view of a validated access token.
+// The iam and ic packages provide implementations wrapping their SDK claims.
+type Claims interface {
+	// GetSubject returns the user ID, empty for client tokens
+	GetSubject() string
+	GetClientID() string
+	// GetScope returns the space separated scopes of the token
+	GetScope() string
+	GetAudience() []string
+	// Attribute returns the request attribute key under which the filter stores Raw(),
+	// so the token specific RetrieveJWTClaims helpers keep working
+	Attribute() string
+	// Raw returns the underlying SDK claims, e.g. *iam.JWTClaims or *ic.JWTClaims
+	Raw() interface{}
+}
+
+// Verifier validates an access token and returns its claims.
+type Verifier interface {
+	Verify(token string) (Claims, error)
+}
+
+// RefererValidator validates the referer header of a request whose token comes from a cookie, since browsers send
+// cookies with cross-site requests too (CSRF). The verifiers of the iam and ic packages implement it.
+// A Verifier without RefererValidator only accepts tokens from the Authorization header.
+type RefererValidator interface {
+	ValidateReferer(req *restful.Request, claims Claims) bool
+}
+
+// RequestValidator validates a request with a valid token, e.g. that the request host belongs to the token's
+// namespace. Auth responds the returned error like a FilterOption error, PublicAuth doesn't call it.
+// The verifiers of the iam and ic packages implement it with their subdomain validation.
+type RequestValidator interface {
+	ValidateRequest(req *restful.Request, claims Claims) error
+}
+
+// FilterOption extends the basic auth filter functionality
+type FilterOption func(req *restful.Request, claims Claims) error
+
+// Filter handles auth using filter.
+// It accepts a token as soon as one of its verifiers validates it,
+// so a single route can serve both IAM and IC tokens.
+// Tokens from a cookie also need a valid referer header, checked by the verifier as a RefererValidator,
+// and Auth validates the request with the verifier when it is a RequestValidator.
+type Filter struct {
+	verifiers      []Verifier
+	publicAuthMode PublicAuthMode
+	observer       VerificationObserver
+}
+
+// NewFilter creates new Filter instance. Verifiers are tried in the given order.
+// Example:
+//
+//	core.NewFilter(iam.NewVerifier(iamClient), ic.NewVerifier(icClient))
+func NewFilter(verifiers ...Verifier) *Filter {
+	return &Filter{verifiers: verifiers}
+}
+
+// WithPublicAuthMode sets how PublicAuth handles an invalid or expired token and returns the filter.
+func (filter *Filter) WithPublicAuthMode(mode PublicAuthMode) *Filter {
+	filter.publicAuthMode = mode
+	return filter
+}
+
+// Auth returns a filter that filters request with valid access token in auth header or cookie
+// The token's claims will be passed in the request.attributes["AuthClaims"] = Claims
+// and in the token specific attribute returned by Claims.Attribute()
+// This filter is expandable through FilterOption parameter
+// Example:
+// filter.Auth(
+//
+//	WithValidUser(),
+//	WithValidScope("account"),
+//
+// )
+func (filter *Filter) Auth(opts ...FilterOption) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		token, tokenFrom, err := ParseAccessToken(req)
+		if err != nil {
+			logrus.Warn("unauthorized access: ", err)
+			WriteErrorResponse(resp, http.StatusUnauthorized, UnauthorizedAccess, ErrorCodeMapping[UnauthorizedAccess])
+
+			return
+		}
+
+		claims, verifier, err := filter.verifyObserved(token)
+		if err != nil {
+			logrus.Warn("unauthorized access: ", err)
+			WriteVerificationError(resp, ClassifyVerificationError(err))
+			return
+		}
+
+		if tokenFrom == TokenFromCookie && !validateReferer(req, verifier, claims) {
+			WriteErrorResponse(resp, http.StatusUnauthorized, InvalidRefererHeader, ErrorCodeMapping[InvalidRefererHeader])
+
+			return
+		}
+
+		if requestValidator, ok := verifier.(RequestValidator); ok {
+			if err = requestValidator.ValidateRequest(req, claims); err != nil {
+				WriteOptionError(resp, err)
+
+				return
+			}
+		}
+
+		setClaims(req, claims)
+		for _, opt := range opts {
+			if err = opt(req, claims); err != nil {
+				WriteOptionError(resp, err)
+
+				return
+			}
+		}
+
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// WithVerificationObserver sets the observer notified of every rejected token and returns the filter.
+func (filter *Filter) WithVerificationObserver(observer VerificationObserver) *Filter {
+	filter.observer = observer
+	return filter
+}
+
+// PublicAuth returns a filter that allow unauthenticated request and request with valid access token in auth header or cookie
+// If request has access token, the token's claims will be passed in the request.attributes["AuthClaims"] = Claims
+// If request has invalid access token, it is handled according to the filter PublicAuthMode,
+// by default the request is treated as public access without claims
+// When the request ends up without claims, the reason is passed in the request.attributes["AuthClaimsAbsenceReason"]
+// This filter is expandable through FilterOption parameter
+func (filter *Filter) PublicAuth(opts ...FilterOption) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		token, tokenFrom, err := ParseAccessToken(req)
+		if err != nil {
+			SetClaimsAbsenceReason(req, ClaimsAbsenceNoToken)
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		claims, verifier, err := filter.verifyObserved(token)
+		if err != nil {
+			logrus.Warn("unauthorized access for public endpoint: ", err)
+			if RejectInvalidPublicToken(req, resp, filter.publicAuthMode, err) {
+				return
+			}
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		if tokenFrom == TokenFromCookie && !validateReferer(req, verifier, claims) {
+			if RejectPublicToken(req, resp, filter.publicAuthMode, ClaimsAbsenceInvalidReferer) {
+				return
+			}
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		setClaims(req, claims)
+		for _, opt := range opts {
+			if err = opt(req, claims); err != nil {
+				logrus.Warn(err)
+				clearClaims(req, claims)
+				SetClaimsAbsenceReason(req, ClaimsAbsenceOptionRejected)
+				chain.ProcessFilter(req, resp)
+				return
+			}
+		}
+
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// Verify validates the token against each verifier in order and returns the first successful claims.
+// When every verifier rejects the token, an expiry error is preferred over the others
+// since it is the only one the caller can act on.
+func (filter *Filter) Verify(token string) (Claims, error) {
+	claims, _, err := filter.verifyObserved(token)
+	return claims, err
+}
+
+// verifyObserved is Verify also returning the verifier accepting the token.
+func (filter *Filter) verifyObserved(token string) (Claims, Verifier, error) {
+	claims, verifier, err := filter.verify(token)
+	if err != nil {
+		filter.observer.Observe(ClassifyVerificationError(err))
+	}
+
+	return claims, verifier, err
+}
+
+func (filter *Filter) verify(token string) (Claims, Verifier, error) {
+	if len(filter.verifiers) == 0 {
+		return nil, nil, fmt.Errorf("no token verifier configured")
+	}
+
+	var firstErr error
+	for _, verifier := range filter.verifiers {
+		claims, err := verifier.Verify(token)
+		if err == nil {
+			return claims, verifier, nil
+		}
+		if IsTokenExpired(err) {
+			return nil, nil, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, nil, firstErr
+}
+
+// validateReferer checks the referer of a request with a cookie token with the verifier accepting the token.
+// The token is rejected when the verifier can't check it.
+func validateReferer(req *restful.Request, verifier Verifier, claims Claims) bool {
+	refererValidator, ok := verifier.(RefererValidator)
+	if !ok {
+		logrus.Warnf("unauthorized access: token from cookie rejected, its verifier %T doesn't validate the referer header", verifier)
+		return false
+	}
+
+	return refererValidator.ValidateReferer(req, claims)
+}
+
+func setClaims(req *restful.Request, claims Claims) {
+	req.SetAttribute(ClaimsAttribute, claims)
+	if attribute := claims.Attribute(); attribute != "" {
+		req.SetAttribute(attribute, claims.Raw())
+	}
+}
+
+func clearClaims(req *restful.Request, claims Claims) {
+	req.SetAttribute(ClaimsAttribute, nil)
+	if attribute := claims.Attribute(); attribute != "" {
+		req.SetAttribute(attribute, nil)
+	}
+}
+
+// RetrieveClaims is a convenience function to retrieve the generic claims
+// from restful.Request.
+// Warning: the claims can be nil if the request wasn't filtered through Auth()
+func RetrieveClaims(request *restful.Request) Claims {
+	claims, _ := request.Attribute(ClaimsAttribute).(Claims)
+	return claims
+}
+
+// WithValidUser filters request with valid user only
+func WithValidUser() FilterOption {
+	return func(req *restful.Request, claims Claims) error {
+		if claims.GetSubject() == "" {
+			return RespondError(http.StatusForbidden, TokenIsNotUserToken,
+				"access forbidden: "+ErrorCodeMapping[TokenIsNotUserToken])
+		}
+
+		return nil
+	}
+}
+
+// WithValidScope filters request from a token having the given scope
+func WithValidScope(scope string) FilterOption {
+	return func(req *restful.Request, claims Claims) error {
+		if HasScope(claims.GetScope(), scope) {
+			return nil
+		}
+
+		insufficientScopeMessage := ErrorCodeMapping[InsufficientScope]
+		if DevStackTraceable {
+			insufficientScopeMessage = fmt.Sprintf("%s. Required scope: %s", insufficientScopeMessage, scope)
+		}
+
+		return RespondError(http.StatusForbidden, InsufficientScope,
+			"access forbidden: "+insufficientScopeMessage)
+	}
+}
+
+// HasScope reports whether the space separated scopes contain scope.
+func HasScope(scopes string, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,517 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AccelByte/go-jose"
+	"github.com/AccelByte/go-jose/jwt"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClaims struct {
+	subject string
+	scope   string
+	issuer  string
+}
+
+func (c *mockClaims) GetSubject() string    { return c.subject }
+func (c *mockClaims) GetClientID() string   { return "client" }
+func (c *mockClaims) GetScope() string      { return c.scope }
+func (c *mockClaims) GetAudience() []string { return nil }
+func (c *mockClaims) Attribute() string     { return c.issuer + "Claims" }
+func (c *mockClaims) Raw() interface{}      { return c }
+
+type mockVerifier struct {
+	tokens map[string]*mockClaims
+	err    error
+}
+
+func (v *mockVerifier) Verify(token string) (Claims, error) {
+	if claims, ok := v.tokens[token]; ok {
+		return claims, nil
+	}
+	if v.err != nil {
+		return nil, v.err
+	}
+	return nil, errors.New("invalid token")
+}
+
+func newTestRequest(token string) *restful.Request {
+	httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return restful.NewRequest(httpReq)
+}
+
+func runFilter(filter restful.FilterFunction, req *restful.Request) (*httptest.ResponseRecorder, bool) {
+	recorder := httptest.NewRecorder()
+	resp := restful.NewResponse(recorder)
+	called := false
+	chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+	filter(req, resp, chain)
+	return recorder, called
+}
+
+func decodeErrorResponse(t *testing.T, recorder *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var errResp ErrorResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &errResp))
+	return errResp
+}
+
+func newMultiIssuerFilter() *Filter {
+	iamVerifier := &mockVerifier{tokens: map[string]*mockClaims{
+		"iamToken": {subject: "user1", scope: "account commerce", issuer: "IAM"},
+	}}
+	icVerifier := &mockVerifier{tokens: map[string]*mockClaims{
+		"icToken": {subject: "", scope: "account", issuer: "IC"},
+	}}
+	return NewFilter(iamVerifier, icVerifier)
+}
+
+// nolint:paralleltest
+func TestAuth_AcceptsTokenFromAnyVerifier(t *testing.T) {
+	filter := newMultiIssuerFilter()
+
+	for _, token := range []string{"iamToken", "icToken"} {
+		req := newTestRequest(token)
+		_, called := runFilter(filter.Auth(), req)
+
+		assert.True(t, called, token)
+		claims := RetrieveClaims(req)
+		assert.NotNil(t, claims, token)
+		assert.Equal(t, claims, req.Attribute(claims.Attribute()), token)
+	}
+}
+
+// nolint:paralleltest
+func TestAuth_RejectsUnknownToken(t *testing.T) {
+	filter := newMultiIssuerFilter()
+
+	recorder, called := runFilter(filter.Auth(), newTestRequest("unknown"))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, UnauthorizedAccess, decodeErrorResponse(t, recorder).ErrorCode)
+}
+
+// nolint:paralleltest
+func TestAuth_MissingToken(t *testing.T) {
+	filter := newMultiIssuerFilter()
+
+	recorder, called := runFilter(filter.Auth(), newTestRequest(""))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+// nolint:paralleltest
+func TestAuth_PrefersExpiredError(t *testing.T) {
+	filter := NewFilter(
+		&mockVerifier{err: errors.New("invalid key")},
+		&mockVerifier{err: errors.New(ErrorCodeMapping[TokenIsExpired])},
+	)
+
+	recorder, called := runFilter(filter.Auth(), newTestRequest("expired"))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, TokenIsExpired, decodeErrorResponse(t, recorder).ErrorCode)
+}
+
+// nolint:paralleltest
+func TestAuth_WithOptions(t *testing.T) {
+	filter := newMultiIssuerFilter()
+
+	_, called := runFilter(filter.Auth(WithValidUser(), WithValidScope("commerce")), newTestRequest("iamToken"))
+	assert.True(t, called)
+
+	recorder, called := runFilter(filter.Auth(WithValidUser()), newTestRequest("icToken"))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, TokenIsNotUserToken, decodeErrorResponse(t, recorder).ErrorCode)
+
+	recorder, called = runFilter(filter.Auth(WithValidScope("commerce")), newTestRequest("icToken"))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, InsufficientScope, decodeErrorResponse(t, recorder).ErrorCode)
+}
+
+// nolint:paralleltest
+func TestPublicAuth(t *testing.T) {
+	filter := newMultiIssuerFilter()
+
+	req := newTestRequest("")
+	_, called := runFilter(filter.PublicAuth(), req)
+	assert.True(t, called)
+	assert.Nil(t, RetrieveClaims(req))
+
+	req = newTestRequest("unknown")
+	_, called = runFilter(filter.PublicAuth(), req)
+	assert.True(t, called)
+	assert.Nil(t, RetrieveClaims(req))
+
+	req = newTestRequest("icToken")
+	_, called = runFilter(filter.PublicAuth(WithValidUser()), req)
+	assert.True(t, called)
+	assert.Nil(t, RetrieveClaims(req))
+
+	req = newTestRequest("iamToken")
+	_, called = runFilter(filter.PublicAuth(WithValidUser()), req)
+	assert.True(t, called)
+	assert.NotNil(t, RetrieveClaims(req))
+}
+
+// refererCheckingVerifier is a mockVerifier validating the referer of cookie tokens.
+type refererCheckingVerifier struct {
+	mockVerifier
+	referer string
+}
+
+func (v *refererCheckingVerifier) ValidateReferer(req *restful.Request, _ Claims) bool {
+	return req.HeaderParameter("Referer") == v.referer
+}
+
+func newCookieRequest(token, referer string) *restful.Request {
+	httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	httpReq.AddCookie(&http.Cookie{Name: AccessTokenCookieKey, Value: token})
+	if referer != "" {
+		httpReq.Header.Set("Referer", referer)
+	}
+	return restful.NewRequest(httpReq)
+}
+
+// nolint:paralleltest
+func TestAuth_CookieTokenReferer(t *testing.T) {
+	tokens := map[string]*mockClaims{"token": {subject: "user1", issuer: "IAM"}}
+	checking := NewFilter(&refererCheckingVerifier{mockVerifier: mockVerifier{tokens: tokens}, referer: "https://game.io/"})
+
+	_, called := runFilter(checking.Auth(), newCookieRequest("token", "https://game.io/"))
+	assert.True(t, called)
+
+	recorder, called := runFilter(checking.Auth(), newCookieRequest("token", "https://evil.io/"))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, InvalidRefererHeader, decodeErrorResponse(t, recorder).ErrorCode)
+
+	// a verifier that can't check the referer only accepts header tokens
+	unchecked := NewFilter(&mockVerifier{tokens: tokens})
+	recorder, called = runFilter(unchecked.Auth(), newCookieRequest("token", "https://game.io/"))
+	assert.False(t, called)
+	assert.Equal(t, InvalidRefererHeader, decodeErrorResponse(t, recorder).ErrorCode)
+
+	_, called = runFilter(unchecked.Auth(), newTestRequest("token"))
+	assert.True(t, called)
+}
+
+// nolint:paralleltest
+func TestPublicAuth_CookieTokenReferer(t *testing.T) {
+	tokens := map[string]*mockClaims{"token": {subject: "user1", issuer: "IAM"}}
+	filter := NewFilter(&refererCheckingVerifier{mockVerifier: mockVerifier{tokens: tokens}, referer: "https://game.io/"})
+
+	req := newCookieRequest("token", "https://evil.io/")
+	_, called := runFilter(filter.PublicAuth(), req)
+	assert.True(t, called)
+	assert.Nil(t, RetrieveClaims(req))
+	assert.Equal(t, ClaimsAbsenceInvalidReferer, RetrieveClaimsAbsenceReason(req))
+
+	recorder, called := runFilter(filter.WithPublicAuthMode(PublicAuthRejectInvalidToken).PublicAuth(), newCookieRequest("token", "https://evil.io/"))
+	assert.False(t, called)
+	assert.Equal(t, InvalidRefererHeader, decodeErrorResponse(t, recorder).ErrorCode)
+
+	req = newCookieRequest("token", "https://game.io/")
+	_, called = runFilter(filter.PublicAuth(), req)
+	assert.True(t, called)
+	assert.NotNil(t, RetrieveClaims(req))
+}
+
+// hostCheckingVerifier is a mockVerifier only accepting requests to host.
+type hostCheckingVerifier struct {
+	mockVerifier
+	host string
+}
+
+func (v *hostCheckingVerifier) ValidateRequest(req *restful.Request, _ Claims) error {
+	if req.Request.Host != v.host {
+		return RespondError(http.StatusNotFound, SubdomainMismatch, "data not found: "+ErrorCodeMapping[SubdomainMismatch])
+	}
+	return nil
+}
+
+// nolint:paralleltest
+func TestAuth_RequestValidator(t *testing.T) {
+	tokens := map[string]*mockClaims{"token": {subject: "user1", issuer: "IAM"}}
+	filter := NewFilter(&hostCheckingVerifier{mockVerifier: mockVerifier{tokens: tokens}, host: "game.io"})
+
+	req := newTestRequest("token")
+	req.Request.Host = "game.io"
+	_, called := runFilter(filter.Auth(), req)
+	assert.True(t, called)
+
+	req = newTestRequest("token")
+	req.Request.Host = "other.io"
+	recorder, called := runFilter(filter.Auth(), req)
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, SubdomainMismatch, decodeErrorResponse(t, recorder).ErrorCode)
+
+	// PublicAuth doesn't validate the request
+	req = newTestRequest("token")
+	req.Request.Host = "other.io"
+	_, called = runFilter(filter.PublicAuth(), req)
+	assert.True(t, called)
+	assert.NotNil(t, RetrieveClaims(req))
+}
+
+// nolint:paralleltest
+func TestParseAccessToken(t *testing.T) {
+	httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	httpReq.Header.Set("Authorization", "BEARER headerToken")
+	token, from, err := ParseAccessToken(restful.NewRequest(httpReq))
+	assert.NoError(t, err)
+	assert.Equal(t, "headerToken", token)
+	assert.Equal(t, TokenFromHeader, from)
+
+	httpReq = httptest.NewRequest(http.MethodGet, "/", nil)
+	httpReq.AddCookie(&http.Cookie{Name: AccessTokenCookieKey, Value: "cookieToken"})
+	token, from, err = ParseAccessToken(restful.NewRequest(httpReq))
+	assert.NoError(t, err)
+	assert.Equal(t, "cookieToken", token)
+	assert.Equal(t, TokenFromCookie, from)
+
+	_, _, err = ParseAccessToken(restful.NewRequest(httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.Error(t, err)
+}
+
+// nolint:paralleltest
+func TestDevStackTraceableFromEnv(t *testing.T) {
+	t.Setenv("REALM_NAME", "dev")
+	assert.True(t, DevStackTraceableFromEnv())
+
+	t.Setenv("REALM_NAME", "prod")
+	assert.False(t, DevStackTraceableFromEnv())
+
+	t.Setenv("REALM_LIVE", "staging")
+	t.Setenv("REALM_NAME", "staging")
+	assert.False(t, DevStackTraceableFromEnv())
+}
+
+// nolint:paralleltest
+func TestActionConverter(t *testing.T) {
+	assert.Equal(t, "READ", ActionConverter(ActionRead))
+	assert.Equal(t, "CREATE", ActionConverter(ActionCreate))
+	assert.Equal(t, "UPDATE", ActionConverter(ActionUpdate))
+	assert.Equal(t, "DELETE", ActionConverter(ActionDelete))
+	assert.Equal(t, "", ActionConverter(ActionRead|ActionCreate))
+}
+
+// nolint:paralleltest
+func TestPublicAuth_Modes(t *testing.T) {
+	testcases := []struct {
+		name           string
+		mode           PublicAuthMode
+		token          string
+		verifierErr    error
+		called         bool
+		reason         ClaimsAbsenceReason
+		rejectedHeader string
+		errorCode      int
+	}{
+		{name: "anonymous: no token", mode: PublicAuthAnonymous, called: true, reason: ClaimsAbsenceNoToken},
+		{name: "anonymous: invalid token", mode: PublicAuthAnonymous, token: "unknown", called: true, reason: ClaimsAbsenceInvalidToken},
+		{name: "anonymous: valid token", mode: PublicAuthAnonymous, token: "iamToken", called: true},
+		{name: "flag: no token", mode: PublicAuthFlagInvalidToken, called: true, reason: ClaimsAbsenceNoToken},
+		{
+			name: "flag: invalid token", mode: PublicAuthFlagInvalidToken, token: "unknown", called: true,
+			reason: ClaimsAbsenceInvalidToken, rejectedHeader: "invalid_token",
+		},
+		{
+			name: "flag: expired token", mode: PublicAuthFlagInvalidToken, token: "unknown", verifierErr: errors.New(ErrorCodeMapping[TokenIsExpired]),
+			called: true, reason: ClaimsAbsenceExpiredToken, rejectedHeader: "expired_token",
+		},
+		{name: "reject: no token", mode: PublicAuthRejectInvalidToken, called: true, reason: ClaimsAbsenceNoToken},
+		{name: "reject: valid token", mode: PublicAuthRejectInvalidToken, token: "iamToken", called: true},
+		{
+			name: "reject: invalid token", mode: PublicAuthRejectInvalidToken, token: "unknown",
+			reason: ClaimsAbsenceInvalidToken, errorCode: UnauthorizedAccess,
+		},
+		{
+			name: "reject: expired token", mode: PublicAuthRejectInvalidToken, token: "unknown", verifierErr: errors.New(ErrorCodeMapping[TokenIsExpired]),
+			reason: ClaimsAbsenceExpiredToken, errorCode: TokenIsExpired,
+		},
+		{
+			name: "reject: malformed token", mode: PublicAuthRejectInvalidToken, token: "unknown", verifierErr: errors.New("unable to parse JWT"),
+			reason: ClaimsAbsenceInvalidToken, errorCode: MalformedToken,
+		},
+		{
+			name: "reject: unknown signing key", mode: PublicAuthRejectInvalidToken, token: "unknown",
+			verifierErr: errors.New("getPublicKey: public key doesn't exist"), reason: ClaimsAbsenceInvalidToken, errorCode: UnknownTokenSigningKey,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			filter := NewFilter(&mockVerifier{
+				tokens: map[string]*mockClaims{"iamToken": {subject: "user1", issuer: "IAM"}},
+				err:    testcase.verifierErr,
+			}).WithPublicAuthMode(testcase.mode)
+
+			req := newTestRequest(testcase.token)
+			recorder, called := runFilter(filter.PublicAuth(), req)
+
+			assert.Equal(t, testcase.called, called)
+			assert.Equal(t, testcase.reason, RetrieveClaimsAbsenceReason(req))
+			assert.Equal(t, testcase.rejectedHeader, recorder.Header().Get(RejectedTokenHeader))
+			if testcase.errorCode != 0 {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, testcase.errorCode, decodeErrorResponse(t, recorder).ErrorCode)
+			}
+		})
+	}
+}
+
+// nolint:paralleltest
+func TestPublicAuth_OptionRejected(t *testing.T) {
+	filter := newMultiIssuerFilter().WithPublicAuthMode(PublicAuthRejectInvalidToken)
+
+	req := newTestRequest("icToken")
+	_, called := runFilter(filter.PublicAuth(WithValidUser()), req)
+
+	assert.True(t, called)
+	assert.Nil(t, RetrieveClaims(req))
+	assert.Equal(t, ClaimsAbsenceOptionRejected, RetrieveClaimsAbsenceReason(req))
+}
+
+// nolint:paralleltest
+func TestParsePublicAuthMode(t *testing.T) {
+	for value, expected := range map[string]PublicAuthMode{
+		"anonymous": PublicAuthAnonymous,
+		" Flag ":    PublicAuthFlagInvalidToken,
+		"REJECT":    PublicAuthRejectInvalidToken,
+	} {
+		mode, err := ParsePublicAuthMode(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, mode, value)
+	}
+
+	mode, err := ParsePublicAuthMode("strict")
+	assert.Error(t, err)
+	assert.Equal(t, PublicAuthAnonymous, mode)
+}
+
+// nolint:paralleltest
+func TestClassifyVerificationError(t *testing.T) {
+	testcases := []struct {
+		err      error
+		expected VerificationFailure
+	}{
+		{err: errors.New("token is expired"), expected: VerificationFailureExpired},
+		{err: fmt.Errorf("validateJWT: %w", jwt.ErrExpired), expected: VerificationFailureExpired},
+		{err: fmt.Errorf("validateJWT: %w", jwt.ErrNotValidYet), expected: VerificationFailureNotYetValid},
+		{err: errors.New("validateJWT: unable to validate JWT: square/go-jose/jwt: validation failed, token not valid yet (nbf)"), expected: VerificationFailureNotYetValid},
+		{err: fmt.Errorf("validateJWT: %w", jose.ErrCryptoFailure), expected: VerificationFailureBadSignature},
+		{err: errors.New("validateJWT: unable to deserialize JWT claims: square/go-jose: error in cryptographic primitive"), expected: VerificationFailureBadSignature},
+		{err: errors.New("validateJWT: invalid key: getPublicKey: public key doesn't exist"), expected: VerificationFailureUnknownKeyID},
+		{err: errors.New("token has been revoked"), expected: VerificationFailureRevoked},
+		{err: errors.New("validateJWT: unable to parse JWT: square/go-jose: compact JWS format must have three parts"), expected: VerificationFailureMalformed},
+		{err: errors.New("validateJWT: invalid header: invalid token signature key ID"), expected: VerificationFailureMalformed},
+		{err: errors.New("validateJWT: invalid token: token is empty"), expected: VerificationFailureMalformed},
+		{err: &VerificationError{Failure: VerificationFailureRevoked, Err: errors.New("banned")}, expected: VerificationFailureRevoked},
+		{err: errors.New("something else"), expected: VerificationFailureInvalid},
+	}
+
+	for _, testcase := range testcases {
+		assert.Equal(t, testcase.expected, ClassifyVerificationError(testcase.err), testcase.err.Error())
+	}
+}
+
+// nolint:paralleltest
+func TestAuth_VerificationErrorCodes(t *testing.T) {
+	testcases := []struct {
+		err       error
+		errorCode int
+		failure   VerificationFailure
+	}{
+		{err: errors.New("getPublicKey: public key doesn't exist"), errorCode: UnknownTokenSigningKey, failure: VerificationFailureUnknownKeyID},
+		{err: jwt.ErrNotValidYet, errorCode: TokenIsNotYetValid, failure: VerificationFailureNotYetValid},
+		{err: jose.ErrCryptoFailure, errorCode: InvalidTokenSignature, failure: VerificationFailureBadSignature},
+		{err: errors.New("token revoked"), errorCode: TokenIsRevoked, failure: VerificationFailureRevoked},
+		{err: errors.New("unable to parse JWT"), errorCode: MalformedToken, failure: VerificationFailureMalformed},
+		{err: errors.New("nope"), errorCode: UnauthorizedAccess, failure: VerificationFailureInvalid},
+	}
+
+	for _, testcase := range testcases {
+		var observed []VerificationFailure
+		filter := NewFilter(&mockVerifier{err: testcase.err}).
+			WithVerificationObserver(func(failure VerificationFailure) { observed = append(observed, failure) })
+
+		recorder, called := runFilter(filter.Auth(), newTestRequest("token"))
+
+		assert.False(t, called)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, testcase.errorCode, decodeErrorResponse(t, recorder).ErrorCode, testcase.err.Error())
+		assert.Equal(t, []VerificationFailure{testcase.failure}, observed)
+	}
+}
+
+type mockKeyRefresher struct {
+	refreshed int
+}
+
+func (r *mockKeyRefresher) RefreshKeys() error {
+	r.refreshed++
+	return nil
+}
+
+// nolint:paralleltest
+func TestValidateWithKeyRefresh(t *testing.T) {
+	refresher := &mockKeyRefresher{}
+	guard := &KeyRefreshGuard{Interval: time.Hour}
+	unknownKeyErr := errors.New("getPublicKey: public key doesn't exist")
+
+	validate := func() (string, error) {
+		if refresher.refreshed == 0 {
+			return "", unknownKeyErr
+		}
+		return "claims", nil
+	}
+
+	claims, err := ValidateWithKeyRefresh(refresher, guard, validate)
+	assert.NoError(t, err)
+	assert.Equal(t, "claims", claims)
+	assert.Equal(t, 1, refresher.refreshed)
+
+	// the guard skips the refresh within the interval
+	_, err = ValidateWithKeyRefresh(refresher, guard, func() (string, error) { return "", unknownKeyErr })
+	assert.Equal(t, unknownKeyErr, err)
+	assert.Equal(t, 1, refresher.refreshed)
+
+	// other failures and clients without KeyRefresher are not refreshed
+	_, err = ValidateWithKeyRefresh(refresher, &KeyRefreshGuard{}, func() (string, error) { return "", jwt.ErrExpired })
+	assert.Equal(t, jwt.ErrExpired, err)
+	_, err = ValidateWithKeyRefresh("client", &KeyRefreshGuard{}, func() (string, error) { return "", unknownKeyErr })
+	assert.Equal(t, unknownKeyErr, err)
+	assert.Equal(t, 1, refresher.refreshed)
+}
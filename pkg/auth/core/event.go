@@ -0,0 +1,53 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// Global Error Codes shared by every auth filter.
+// The values follow the AccelByte error code standard used by the iam package.
+const (
+	InternalServerError     = 20000
+	UnauthorizedAccess      = 20001
+	ForbiddenAccess         = 20003
+	TokenIsExpired          = 20011
+	InsufficientPermissions = 20013
+	InvalidAudience         = 20014
+	InsufficientScope       = 20015
+	TokenIsNotUserToken     = 20022
+	InvalidRefererHeader    = 20023
+	TokenIsNotYetValid      = 20024
+	InvalidTokenSignature   = 20025
+	UnknownTokenSigningKey  = 20026
+	TokenIsRevoked          = 20027
+	MalformedToken          = 20028
+	SubdomainMismatch       = 20030
+)
+
+var ErrorCodeMapping = map[int]string{
+	InternalServerError:     "internal server error",
+	UnauthorizedAccess:      "unauthorized access",
+	ForbiddenAccess:         "forbidden access",
+	TokenIsExpired:          "token is expired",
+	InsufficientPermissions: "insufficient permissions",
+	InvalidAudience:         "invalid audience",
+	InsufficientScope:       "insufficient scope",
+	TokenIsNotUserToken:     "token is not user token",
+	InvalidRefererHeader:    "invalid referer header",
+	TokenIsNotYetValid:      "token is not yet valid",
+	InvalidTokenSignature:   "invalid token signature",
+	UnknownTokenSigningKey:  "unknown token signing key",
+	TokenIsRevoked:          "token is revoked",
+	MalformedToken:          "malformed token",
+	SubdomainMismatch:       "subdomain mismatch",
+}
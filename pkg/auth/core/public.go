@@ -0,0 +1,135 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+const (
+	// ClaimsAbsenceReasonAttribute is the key for the ClaimsAbsenceReason stored in the request by PublicAuth
+	ClaimsAbsenceReasonAttribute = "AuthClaimsAbsenceReason"
+
+	// RejectedTokenHeader is the response header set by PublicAuthFlagInvalidToken,
+	// its value is the ClaimsAbsenceReason
+	RejectedTokenHeader = "X-Auth-Token-Rejected"
+)
+
+// PublicAuthMode tells PublicAuth how to handle a request carrying a token that can't be used.
+type PublicAuthMode int
+
+const (
+	// PublicAuthAnonymous treats the caller as anonymous, this is the default
+	PublicAuthAnonymous PublicAuthMode = iota
+	// PublicAuthFlagInvalidToken treats the caller as anonymous and sets RejectedTokenHeader on the response
+	PublicAuthFlagInvalidToken
+	// PublicAuthRejectInvalidToken responds 401 the same way Auth does
+	PublicAuthRejectInvalidToken
+)
+
+var publicAuthModeNames = map[string]PublicAuthMode{
+	"anonymous": PublicAuthAnonymous,
+	"flag":      PublicAuthFlagInvalidToken,
+	"reject":    PublicAuthRejectInvalidToken,
+}
+
+// ParsePublicAuthMode parses "anonymous", "flag" or "reject", case insensitive.
+func ParsePublicAuthMode(s string) (PublicAuthMode, error) {
+	mode, ok := publicAuthModeNames[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return PublicAuthAnonymous, fmt.Errorf("unknown public auth mode %q", s)
+	}
+
+	return mode, nil
+}
+
+// ClaimsAbsenceReason records why PublicAuth left a request without claims.
+type ClaimsAbsenceReason string
+
+const (
+	ClaimsAbsenceNoToken        ClaimsAbsenceReason = "no_token"
+	ClaimsAbsenceInvalidToken   ClaimsAbsenceReason = "invalid_token"
+	ClaimsAbsenceExpiredToken   ClaimsAbsenceReason = "expired_token"
+	ClaimsAbsenceInvalidReferer ClaimsAbsenceReason = "invalid_referer"
+	// ClaimsAbsenceOptionRejected means the token is valid but one of the FilterOption rejected it
+	ClaimsAbsenceOptionRejected ClaimsAbsenceReason = "option_rejected"
+)
+
+// RetrieveClaimsAbsenceReason returns why the request has no claims,
+// or an empty reason when it has claims or wasn't filtered through PublicAuth.
+func RetrieveClaimsAbsenceReason(request *restful.Request) ClaimsAbsenceReason {
+	reason, _ := request.Attribute(ClaimsAbsenceReasonAttribute).(ClaimsAbsenceReason)
+	return reason
+}
+
+// SetClaimsAbsenceReason records reason in the request.
+func SetClaimsAbsenceReason(request *restful.Request, reason ClaimsAbsenceReason) {
+	request.SetAttribute(ClaimsAbsenceReasonAttribute, reason)
+}
+
+// RejectPublicToken records reason in the request and applies mode to a token PublicAuth can't use.
+// It returns true when the error response has been written and the filter chain must stop.
+func RejectPublicToken(req *restful.Request, resp *restful.Response, mode PublicAuthMode, reason ClaimsAbsenceReason) bool {
+	SetClaimsAbsenceReason(req, reason)
+
+	switch mode {
+	case PublicAuthFlagInvalidToken:
+		resp.Header().Set(RejectedTokenHeader, string(reason))
+	case PublicAuthRejectInvalidToken:
+		errorCode := UnauthorizedAccess
+		switch reason {
+		case ClaimsAbsenceExpiredToken:
+			errorCode = TokenIsExpired
+		case ClaimsAbsenceInvalidReferer:
+			errorCode = InvalidRefererHeader
+		}
+		WriteErrorResponse(resp, http.StatusUnauthorized, errorCode, ErrorCodeMapping[errorCode])
+
+		return true
+	}
+
+	return false
+}
+
+// RejectInvalidPublicToken is RejectPublicToken for a token that failed verification with err.
+// In PublicAuthRejectInvalidToken mode, it responds the error code of the VerificationFailure of err, like Auth does.
+func RejectInvalidPublicToken(req *restful.Request, resp *restful.Response, mode PublicAuthMode, err error) bool {
+	failure := ClassifyVerificationError(err)
+	if mode != PublicAuthRejectInvalidToken {
+		return RejectPublicToken(req, resp, mode, failure.absenceReason())
+	}
+
+	SetClaimsAbsenceReason(req, failure.absenceReason())
+	WriteVerificationError(resp, failure)
+
+	return true
+}
+
+// TokenAbsenceReason returns the ClaimsAbsenceReason for a token verification error.
+func TokenAbsenceReason(err error) ClaimsAbsenceReason {
+	return ClassifyVerificationError(err).absenceReason()
+}
+
+func (failure VerificationFailure) absenceReason() ClaimsAbsenceReason {
+	if failure == VerificationFailureExpired {
+		return ClaimsAbsenceExpiredToken
+	}
+
+	return ClaimsAbsenceInvalidToken
+}
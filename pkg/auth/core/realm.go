@@ -0,0 +1,78 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"os"
+	"strings"
+
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
+)
+
+// Action bits, identical in the IAM and IC SDKs
+const (
+	ActionCreate = 1
+	ActionRead   = 1 << 1
+	ActionUpdate = 1 << 2
+	ActionDelete = 1 << 3
+)
+
+var DevStackTraceable bool
+
+// DevStackTraceableFromEnv reports whether verbose error messages should be returned.
+// It is true when REALM_NAME is set and is not one of the live realms listed in REALM_LIVE
+// (constant.DefaultRealmLive when unset).
+func DevStackTraceableFromEnv() bool {
+	realmName, realmNameExists := os.LookupEnv("REALM_NAME")
+	if !realmNameExists {
+		return false
+	}
+
+	realmLive, realmLiveExists := os.LookupEnv("REALM_LIVE")
+	if !realmLiveExists {
+		realmLive = constant.DefaultRealmLive
+	}
+
+	realmLives := strings.Split(realmLive, ",")
+	for _, rl := range realmLives {
+		if realmName == rl {
+			return false
+		}
+	}
+
+	return true
+}
+
+func init() {
+	DevStackTraceable = DevStackTraceableFromEnv() // activate verbose insufficient error message in non-prod environment
+}
+
+// ActionConverter convert action bit to human-readable
+func ActionConverter(action int) string {
+	var ActionStr string
+	switch action {
+	case ActionRead:
+		ActionStr = constant.PermissionRead
+	case ActionCreate:
+		ActionStr = constant.PermissionCreate
+	case ActionUpdate:
+		ActionStr = constant.PermissionUpdate
+	case ActionDelete:
+		ActionStr = constant.PermissionDelete
+	default:
+		return ""
+	}
+	return ActionStr
+}
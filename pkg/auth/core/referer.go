@@ -0,0 +1,112 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/util"
+)
+
+// ValidateRefererAgainstRedirectURIs checks the referer header against the comma separated redirect URIs of a client.
+// By default only the domain (scheme+host) has to match. strict additionally requires the referer to start with
+// the redirect URI, and allowSubdomainMatch accepts referers from subdomains of the redirect URI host.
+func ValidateRefererAgainstRedirectURIs(referer string, redirectURIs string, strict bool, allowSubdomainMatch bool) bool {
+	if referer == "" {
+		return false
+	}
+
+	refererDomain := util.GetDomain(referer)
+	for _, redirectURI := range strings.Split(redirectURIs, ",") {
+		if allowSubdomainMatch {
+			if ValidateRefererWithoutSubdomain(referer, redirectURI) {
+				return true
+			}
+		} else {
+			redirectURIDomain := util.GetDomain(redirectURI)
+			if strict {
+				if refererDomain == redirectURIDomain && strings.HasPrefix(referer, redirectURI) {
+					return true
+				}
+			} else {
+				if refererDomain == redirectURIDomain {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// ValidateRefererWithoutSubdomain checks whether the referer host is the client redirect URI host or one of its subdomains.
+func ValidateRefererWithoutSubdomain(refererHeader string, clientRedirectURI string) bool {
+	refererURL, err := url.Parse(refererHeader)
+	if err != nil {
+		return false
+	}
+
+	clientRedirectURL, err := url.Parse(clientRedirectURI)
+	if err != nil {
+		return false
+	}
+
+	if refererURL.Scheme != clientRedirectURL.Scheme {
+		return false
+	}
+
+	// remove the ".www"
+	clientRedirectHost := strings.Replace(clientRedirectURL.Host, "www.", "", 1)
+
+	if strings.HasSuffix(refererURL.Host, clientRedirectHost) {
+		// check the character after the redirectUri string in referer string,
+		// if contains [a-zA-Z] character, then it's not a valid domain
+		// e.g.
+		// redirectUri host: accelbyte.io
+		// referer host: mygame.evilaccelbyte.io
+		if len(refererURL.Host) > len(clientRedirectHost) {
+			if refererURL.Host[len(refererURL.Host)-len(clientRedirectHost)-1] != '.' {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// Subdomain returns the first label of host, or false when host has less than 3 labels, e.g. example.com.
+func Subdomain(host string) (string, bool) {
+	part := strings.Split(host, ".")
+	if len(part) < 3 {
+		return "", false
+	}
+
+	return part[0], true
+}
+
+// GetHost returns the request host without port.
+func GetHost(req *http.Request) string {
+	if !req.URL.IsAbs() {
+		host := req.Host
+		if i := strings.Index(host, ":"); i != -1 {
+			host = host[:i]
+		}
+		return host
+	}
+	return req.URL.Host
+}
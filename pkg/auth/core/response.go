@@ -0,0 +1,113 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorResponse is the generic structure for communicating errors from a REST endpoint.
+type ErrorResponse struct {
+	ErrorCode          int         `json:"errorCode"`
+	ErrorMessage       string      `json:"errorMessage"`
+	RequiredPermission *Permission `json:"requiredPermission,omitempty"`
+}
+
+// Permission is the permission reported back to the caller when a permission check fails.
+type Permission struct {
+	Resource string `json:"resource"`
+	Action   int    `json:"action"`
+}
+
+// RespondError builds a restful.ServiceError carrying a JSON encoded ErrorResponse.
+// FilterOption implementations return it so the filter can write it back to the caller.
+func RespondError(httpStatus, errorCode int, errorMessage string) restful.ServiceError {
+	return respond(httpStatus, ErrorResponse{
+		ErrorCode:    errorCode,
+		ErrorMessage: errorMessage,
+	})
+}
+
+// RespondErrorWithRequiredPermission is RespondError with the required permission attached to the body.
+func RespondErrorWithRequiredPermission(httpStatus, errorCode int, errorMessage string,
+	requiredPermission Permission) restful.ServiceError {
+	return respond(httpStatus, ErrorResponse{
+		ErrorCode:          errorCode,
+		ErrorMessage:       errorMessage,
+		RequiredPermission: &requiredPermission,
+	})
+}
+
+func respond(httpStatus int, errorResponse ErrorResponse) restful.ServiceError {
+	messageByte, err := json.Marshal(errorResponse)
+	if err != nil {
+		errMsgByte, _ := json.Marshal(ErrorResponse{
+			ErrorCode:    InternalServerError,
+			ErrorMessage: "unable to parse error message : " + err.Error(),
+		})
+
+		return restful.ServiceError{
+			Code:    http.StatusInternalServerError,
+			Message: string(errMsgByte),
+		}
+	}
+
+	return restful.ServiceError{
+		Code:    httpStatus,
+		Message: string(messageByte),
+	}
+}
+
+// WriteErrorResponse writes an ErrorResponse with the given status and error code.
+func WriteErrorResponse(resp *restful.Response, httpStatus, errorCode int, errorMessage string) {
+	LogIfErr(resp.WriteHeaderAndJson(httpStatus, ErrorResponse{
+		ErrorCode:    errorCode,
+		ErrorMessage: errorMessage,
+	}, restful.MIME_JSON))
+}
+
+// WriteOptionError writes the error returned by a filter option.
+// A restful.ServiceError created by RespondError is written back as JSON with its own status code,
+// any other error results in 401 with the error text as body.
+func WriteOptionError(resp *restful.Response, err error) {
+	if svcErr, ok := err.(restful.ServiceError); ok {
+		logrus.Warn(svcErr.Message)
+
+		var respErr ErrorResponse
+
+		err = json.Unmarshal([]byte(svcErr.Message), &respErr)
+		if err == nil {
+			LogIfErr(resp.WriteHeaderAndJson(svcErr.Code, respErr, restful.MIME_JSON))
+		} else {
+			LogIfErr(resp.WriteErrorString(svcErr.Code, svcErr.Message))
+		}
+
+		return
+	}
+
+	logrus.Warn(err)
+	LogIfErr(resp.WriteErrorString(http.StatusUnauthorized, err.Error()))
+}
+
+// LogIfErr logs err when it is not nil.
+func LogIfErr(err error) {
+	if err != nil {
+		logrus.Error(err)
+	}
+}
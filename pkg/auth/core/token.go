@@ -0,0 +1,55 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+const (
+	// AccessTokenCookieKey is the name of the cookie holding the access token
+	AccessTokenCookieKey = "access_token"
+
+	// TokenFromCookie and TokenFromHeader tell where ParseAccessToken found the token
+	TokenFromCookie = "cookie"
+	TokenFromHeader = "header"
+)
+
+// ParseAccessToken is used to read token from Authorization Header or Cookie.
+// it will return the token value and token from.
+func ParseAccessToken(request *restful.Request) (string, string, error) {
+	authorization := request.HeaderParameter("Authorization")
+	if strings.HasPrefix(strings.ToLower(authorization), "bearer ") {
+		if token := authorization[len("bearer "):]; token != "" {
+			return token, TokenFromHeader, nil
+		}
+	}
+
+	for _, cookie := range request.Request.Cookies() {
+		if cookie.Name == AccessTokenCookieKey && cookie.Value != "" {
+			return cookie.Value, TokenFromCookie, nil
+		}
+	}
+
+	return "", "", errors.New("token not provided in request header")
+}
+
+// IsTokenExpired reports whether err is the token expiry error returned by the IAM and IC SDKs.
+func IsTokenExpired(err error) bool {
+	return err != nil && ClassifyVerificationError(err) == VerificationFailureExpired
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AccelByte/go-jose"
+	"github.com/AccelByte/go-jose/jwt"
+	"github.com/emicklei/go-restful/v3"
+)
+
+// DefaultKeyRefreshInterval is the minimum time between two forced key refreshes of a KeyRefreshGuard
+const DefaultKeyRefreshInterval = 30 * time.Second
+
+// VerificationFailure classifies why an access token was rejected.
+type VerificationFailure string
+
+const (
+	VerificationFailureInvalid      VerificationFailure = "invalid"
+	VerificationFailureExpired      VerificationFailure = "expired"
+	VerificationFailureNotYetValid  VerificationFailure = "not_yet_valid"
+	VerificationFailureBadSignature VerificationFailure = "bad_signature"
+	VerificationFailureUnknownKeyID VerificationFailure = "unknown_kid"
+	VerificationFailureRevoked      VerificationFailure = "revoked"
+	VerificationFailureMalformed    VerificationFailure = "malformed"
+)
+
+// ErrorCode returns the error code responded for the failure.
+func (failure VerificationFailure) ErrorCode() int {
+	switch failure {
+	case VerificationFailureExpired:
+		return TokenIsExpired
+	case VerificationFailureNotYetValid:
+		return TokenIsNotYetValid
+	case VerificationFailureBadSignature:
+		return InvalidTokenSignature
+	case VerificationFailureUnknownKeyID:
+		return UnknownTokenSigningKey
+	case VerificationFailureRevoked:
+		return TokenIsRevoked
+	case VerificationFailureMalformed:
+		return MalformedToken
+	default:
+		return UnauthorizedAccess
+	}
+}
+
+// VerificationError is a token verification error with its classification.
+// Verifiers may return it to skip the message based classification of the SDK errors.
+type VerificationError struct {
+	Failure VerificationFailure
+	Err     error
+}
+
+func (e *VerificationError) Error() string {
+	if e.Err == nil {
+		return string(e.Failure)
+	}
+	return e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// The SDKs wrap or rewrite the go-jose errors, so their messages are matched too. Order matters.
+var verificationFailureMessages = []struct {
+	failure   VerificationFailure
+	fragments []string
+}{
+	{VerificationFailureExpired, []string{"token is expired"}},
+	{VerificationFailureNotYetValid, []string{"token not valid yet", "issued in the future"}},
+	{VerificationFailureRevoked, []string{"revoked"}},
+	{VerificationFailureUnknownKeyID, []string{"public key doesn't exist", "public key does not exist"}},
+	{VerificationFailureMalformed, []string{
+		"unable to parse jwt", "token is empty", "compact jws format", "illegal base64", "invalid character",
+		"unexpected end of json input", "invalid token signature key id",
+	}},
+	{VerificationFailureBadSignature, []string{"error in cryptographic primitive", "signature"}},
+}
+
+// ClassifyVerificationError returns the VerificationFailure of an error returned by a token verification.
+// Errors that can't be classified are VerificationFailureInvalid.
+func ClassifyVerificationError(err error) VerificationFailure {
+	var verificationErr *VerificationError
+	switch {
+	case errors.As(err, &verificationErr):
+		return verificationErr.Failure
+	case errors.Is(err, jwt.ErrExpired):
+		return VerificationFailureExpired
+	case errors.Is(err, jwt.ErrNotValidYet):
+		return VerificationFailureNotYetValid
+	case errors.Is(err, jose.ErrCryptoFailure):
+		return VerificationFailureBadSignature
+	case err == nil:
+		return VerificationFailureInvalid
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, candidate := range verificationFailureMessages {
+		for _, fragment := range candidate.fragments {
+			if strings.Contains(message, fragment) {
+				return candidate.failure
+			}
+		}
+	}
+
+	return VerificationFailureInvalid
+}
+
+// WriteVerificationError responds 401 with the error code of the failure.
+func WriteVerificationError(resp *restful.Response, failure VerificationFailure) {
+	errorCode := failure.ErrorCode()
+	WriteErrorResponse(resp, http.StatusUnauthorized, errorCode, ErrorCodeMapping[errorCode])
+}
+
+// VerificationObserver is notified of every rejected token, e.g. to count them in a metric labeled by failure.
+type VerificationObserver func(failure VerificationFailure)
+
+// Observe calls the observer when it is set.
+func (observer VerificationObserver) Observe(failure VerificationFailure) {
+	if observer != nil {
+		observer(failure)
+	}
+}
+
+// KeyRefresher is implemented by SDK clients and verifiers able to reload their signing keys (JWKS) on demand.
+type KeyRefresher interface {
+	RefreshKeys() error
+}
+
+// KeyRefreshGuard limits forced key refreshes, so tokens with random key IDs can't hammer the JWKS endpoint.
+// The zero value uses DefaultKeyRefreshInterval.
+type KeyRefreshGuard struct {
+	Interval time.Duration
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+}
+
+// Refresh refreshes the keys of refresher unless it was done less than Interval ago.
+// It returns true when the keys were refreshed.
+func (guard *KeyRefreshGuard) Refresh(refresher KeyRefresher) bool {
+	interval := guard.Interval
+	if interval == 0 {
+		interval = DefaultKeyRefreshInterval
+	}
+
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+
+	if !guard.lastRefresh.IsZero() && time.Since(guard.lastRefresh) < interval {
+		return false
+	}
+	guard.lastRefresh = time.Now()
+
+	return refresher.RefreshKeys() == nil
+}
+
+// ValidateWithKeyRefresh calls validate and, when the token is signed with an unknown key ID and client
+// implements KeyRefresher, refreshes the keys and validates the token once more before rejecting it.
+func ValidateWithKeyRefresh[T any](client interface{}, guard *KeyRefreshGuard, validate func() (T, error)) (T, error) {
+	result, err := validate()
+	if err == nil || ClassifyVerificationError(err) != VerificationFailureUnknownKeyID {
+		return result, err
+	}
+
+	refresher, ok := client.(KeyRefresher)
+	if !ok || !guard.Refresh(refresher) {
+		return result, err
+	}
+
+	return validate()
+}
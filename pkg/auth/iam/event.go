@@ -14,6 +14,8 @@
 
 package iam
 
+import "github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
+
 const (
 	EIDWithValidUserNonUserAccessToken            = 1154001
 	EIDWithPermissionUnableValidatePermission     = 1155001
@@ -30,21 +32,26 @@ const (
 
 const (
 	// Global Error Codes
-	InternalServerError         = 20000
-	UnauthorizedAccess          = 20001
+	InternalServerError         = core.InternalServerError
+	UnauthorizedAccess          = core.UnauthorizedAccess
 	ValidationError             = 20002
-	ForbiddenAccess             = 20003
+	ForbiddenAccess             = core.ForbiddenAccess
 	TooManyRequests             = 20007
 	UserNotFound                = 20008
-	TokenIsExpired              = 20011
-	InsufficientPermissions     = 20013
-	InvalidAudience             = 20014
-	InsufficientScope           = 20015
+	TokenIsExpired              = core.TokenIsExpired
+	InsufficientPermissions     = core.InsufficientPermissions
+	InvalidAudience             = core.InvalidAudience
+	InsufficientScope           = core.InsufficientScope
 	UnableToParseRequestBody    = 20019
 	InvalidPaginationParameters = 20021
-	TokenIsNotUserToken         = 20022
-	InvalidRefererHeader        = 20023
-	SubdomainMismatch           = 20030
+	TokenIsNotUserToken         = core.TokenIsNotUserToken
+	InvalidRefererHeader        = core.InvalidRefererHeader
+	TokenIsNotYetValid          = core.TokenIsNotYetValid
+	InvalidTokenSignature       = core.InvalidTokenSignature
+	UnknownTokenSigningKey      = core.UnknownTokenSigningKey
+	TokenIsRevoked              = core.TokenIsRevoked
+	MalformedToken              = core.MalformedToken
+	SubdomainMismatch           = core.SubdomainMismatch
 	UserBanned                  = 20040
 	InsufficientSubscription    = 20050
 )
@@ -64,6 +71,11 @@ var ErrorCodeMapping = map[int]string{
 	InvalidPaginationParameters: "invalid pagination parameter",
 	TokenIsNotUserToken:         "token is not user token",
 	InvalidRefererHeader:        "invalid referer header",
+	TokenIsNotYetValid:          "token is not yet valid",
+	InvalidTokenSignature:       "invalid token signature",
+	UnknownTokenSigningKey:      "unknown token signing key",
+	TokenIsRevoked:              "token is revoked",
+	MalformedToken:              "malformed token",
 	SubdomainMismatch:           "subdomain mismatch",
 	TokenIsExpired:              "token is expired",
 	UserBanned:                  "user banned",
@@ -15,8 +15,6 @@
 package iam
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -25,8 +23,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/util"
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
 	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/hostmap"
 	"github.com/AccelByte/iam-go-sdk/v2"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/sirupsen/logrus"
@@ -36,10 +35,6 @@ const (
 	// ClaimsAttribute is the key for JWT claims stored in the request
 	ClaimsAttribute = "JWTClaims"
 
-	accessTokenCookieKey = "access_token"
-	tokenFromCookie      = "cookie"
-	tokenFromHeader      = "header"
-
 	MatchmakingBanTopic = "MATCHMAKING"
 	ChatBanTopic        = "CHAT"
 
@@ -57,34 +52,32 @@ type FilterOption func(req *restful.Request, iamClient iam.Client, claims *iam.J
 
 // FilterInitializationOptions hold options for Filter during initialization
 type FilterInitializationOptions struct {
-	StrictRefererHeaderValidation              bool     // Enable full path check of redirect uri in referer header validation
-	AllowSubdomainMatchRefererHeaderValidation bool     // Allow checking with subdomain
-	SubdomainValidationEnabled                 bool     // Enable subdomain validation. When it is true, it will match the subdomain in the request url against claims namespace.
-	SubdomainValidationExcludedNamespaces      []string // List of namespaces to be excluded for subdomain validation. When it is not emtpy and the SUBDOMAIN_VALIDATION_ENABLED is true, it will ignore specified namespaces when doing the subdomain validation.
+	StrictRefererHeaderValidation              bool                      // Enable full path check of redirect uri in referer header validation
+	AllowSubdomainMatchRefererHeaderValidation bool                      // Allow checking with subdomain
+	SubdomainValidationEnabled                 bool                      // Enable subdomain validation. When it is true, it will match the subdomain in the request url against claims namespace.
+	SubdomainValidationExcludedNamespaces      []string                  // List of namespaces to be excluded for subdomain validation. When it is not emtpy and the SUBDOMAIN_VALIDATION_ENABLED is true, it will ignore specified namespaces when doing the subdomain validation.
+	PublicAuthMode                             core.PublicAuthMode       // How PublicAuth handles an invalid or expired token. Default treats the caller as anonymous.
+	VerificationObserver                       core.VerificationObserver // Notified of every rejected token, e.g. to count them in a metric labeled by failure.
+	KeyRefreshInterval                         time.Duration             // Minimum time between two key refreshes forced by an unknown key ID, when the client implements core.KeyRefresher. Default core.DefaultKeyRefreshInterval.
+	HostMapping                                *hostmap.Table            // Maps the request and referer hosts to namespaces for subdomain validation, e.g. ns.dev.accelbyte.io or vanity domains. Default: the first label of hosts with at least 3 labels.
 }
 
 // Filter handles auth using filter
 type Filter struct {
-	iamClient iam.Client
-	options   *FilterInitializationOptions
+	iamClient       iam.Client
+	options         *FilterInitializationOptions
+	keyRefreshGuard *core.KeyRefreshGuard
 }
 
 // ErrorResponse is the generic structure for communicating errors from a REST endpoint.
-type ErrorResponse struct {
-	ErrorCode          int         `json:"errorCode"`
-	ErrorMessage       string      `json:"errorMessage"`
-	RequiredPermission *Permission `json:"requiredPermission,omitempty"`
-}
+type ErrorResponse = core.ErrorResponse
 
-type Permission struct {
-	Resource string `json:"resource"`
-	Action   int    `json:"action"`
-}
+// Permission is the required permission reported in ErrorResponse
+type Permission = core.Permission
 
 // NewFilter creates new Filter instance
 func NewFilter(client iam.Client) *Filter {
-	options := &FilterInitializationOptions{}
-	return &Filter{iamClient: client, options: options}
+	return NewFilterWithOptions(client, nil)
 }
 
 // NewFilterWithOptions creates new Filter instance with Options
@@ -97,9 +90,13 @@ func NewFilter(client iam.Client) *Filter {
 //	})
 func NewFilterWithOptions(client iam.Client, options *FilterInitializationOptions) *Filter {
 	if options == nil {
-		return &Filter{iamClient: client, options: &FilterInitializationOptions{}}
+		options = &FilterInitializationOptions{}
+	}
+	return &Filter{
+		iamClient:       client,
+		options:         options,
+		keyRefreshGuard: &core.KeyRefreshGuard{Interval: options.KeyRefreshInterval},
 	}
-	return &Filter{iamClient: client, options: options}
 }
 
 func FilterInitializationOptionsFromEnv() *FilterInitializationOptions {
@@ -122,6 +119,23 @@ func FilterInitializationOptionsFromEnv() *FilterInitializationOptions {
 		options.SubdomainValidationExcludedNamespaces = strings.Split(s, ",")
 	}
 
+	if s, exists := os.LookupEnv("SUBDOMAIN_VALIDATION_SUFFIX_RULES"); exists {
+		rules, err := hostmap.ParseSuffixRules(s)
+		if err != nil {
+			logrus.Errorf("Parse SUBDOMAIN_VALIDATION_SUFFIX_RULES env error: %v", err)
+		} else if len(rules) > 0 {
+			options.HostMapping = &hostmap.Table{Suffixes: rules}
+		}
+	}
+
+	if s, exists := os.LookupEnv("PUBLIC_AUTH_MODE"); exists {
+		mode, err := core.ParsePublicAuthMode(s)
+		if err != nil {
+			logrus.Errorf("Parse PUBLIC_AUTH_MODE env error: %v", err)
+		}
+		options.PublicAuthMode = mode
+	}
+
 	return options
 }
 
@@ -156,91 +170,14 @@ func (filter *Filter) AuthAllowEmptySubdomain(opts ...FilterOption) restful.Filt
 }
 
 func (filter *Filter) authFunc(allowEmptySubdomain bool, opts ...FilterOption) restful.FilterFunction {
-	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-		token, tokenFrom, err := parseAccessToken(req)
-		if err != nil {
-			logrus.Warn("unauthorized access: ", err)
-			logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-				ErrorCode:    UnauthorizedAccess,
-				ErrorMessage: ErrorCodeMapping[UnauthorizedAccess],
-			}, restful.MIME_JSON))
-
-			return
-		}
-
-		claims, err := filter.iamClient.ValidateAndParseClaims(token)
-		if err != nil {
-			logrus.Warn("unauthorized access: ", err)
-			if err.Error() == ErrorCodeMapping[TokenIsExpired] {
-				logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-					ErrorCode:    TokenIsExpired,
-					ErrorMessage: ErrorCodeMapping[TokenIsExpired],
-				}, restful.MIME_JSON))
-				return
-			}
-			logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-				ErrorCode:    UnauthorizedAccess,
-				ErrorMessage: ErrorCodeMapping[UnauthorizedAccess],
-			}, restful.MIME_JSON))
-			return
-		}
-
-		req.SetAttribute(ClaimsAttribute, claims)
-
-		if tokenFrom == tokenFromCookie {
-			valid := filter.validateRefererHeader(req, claims, allowEmptySubdomain)
-			if !valid {
-				logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-					ErrorCode:    InvalidRefererHeader,
-					ErrorMessage: ErrorCodeMapping[InvalidRefererHeader],
-				}, restful.MIME_JSON))
-
-				return
-			}
-		}
-
-		if filter.options.SubdomainValidationEnabled && !allowEmptySubdomain {
-			if valid := validateSubdomainAgainstNamespace(getHost(req.Request), claims.Namespace, filter.options.SubdomainValidationExcludedNamespaces); !valid {
-				logIfErr(resp.WriteHeaderAndJson(http.StatusNotFound, ErrorResponse{
-					ErrorCode:    SubdomainMismatch,
-					ErrorMessage: "data not found: " + ErrorCodeMapping[SubdomainMismatch],
-				}, restful.MIME_JSON))
-
-				return
-			}
-		}
-
-		for _, opt := range opts {
-			if err = opt(req, filter.iamClient, claims); err != nil {
-				if svcErr, ok := err.(restful.ServiceError); ok {
-					logrus.Warn(svcErr.Message)
-
-					var respErr ErrorResponse
-
-					err = json.Unmarshal([]byte(svcErr.Message), &respErr)
-					if err == nil {
-						logIfErr(resp.WriteHeaderAndJson(svcErr.Code, respErr, restful.MIME_JSON))
-					} else {
-						logIfErr(resp.WriteErrorString(svcErr.Code, svcErr.Message))
-					}
-
-					return
-				}
-
-				logrus.Warn(err)
-				logIfErr(resp.WriteErrorString(http.StatusUnauthorized, err.Error()))
-
-				return
-			}
-		}
-
-		chain.ProcessFilter(req, resp)
-	}
+	return filter.coreFilter(allowEmptySubdomain).Auth(filter.coreOptions(opts)...)
 }
 
 // PublicAuth returns a filter that allow unauthenticate request and request with valid access token in auth header or cookie
 // If request has acces token, the token's claims will be passed in the request.attributes["JWTClaims"] = *iam.JWTClaims{}
-// If request has invalid access token, then request treated as public access without claims
+// If request has invalid access token, it is handled according to FilterInitializationOptions.PublicAuthMode,
+// by default the request is treated as public access without claims
+// When the request ends up without claims, the reason can be read with core.RetrieveClaimsAbsenceReason
 // This filter is expandable through FilterOption parameter
 // Example:
 // iam.PublicAuth(
@@ -250,42 +187,26 @@ func (filter *Filter) authFunc(allowEmptySubdomain bool, opts ...FilterOption) r
 //
 // )
 func (filter *Filter) PublicAuth(opts ...FilterOption) restful.FilterFunction {
-	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-		token, tokenFrom, err := parseAccessToken(req)
-		if err != nil {
-			chain.ProcessFilter(req, resp)
-			return
-		}
-
-		claims, err := filter.iamClient.ValidateAndParseClaims(token)
-		if err != nil {
-			logrus.Warn("unauthorized access for public endpoint: ", err)
-			chain.ProcessFilter(req, resp)
-			return
-		}
-
-		req.SetAttribute(ClaimsAttribute, claims)
-
-		if tokenFrom == tokenFromCookie {
-			valid := filter.validateRefererHeader(req, claims, false)
-			if !valid {
-				req.SetAttribute(ClaimsAttribute, nil)
-				chain.ProcessFilter(req, resp)
-				return
-			}
-		}
+	return filter.coreFilter(false).PublicAuth(filter.coreOptions(opts)...)
+}
 
-		for _, opt := range opts {
-			if err = opt(req, filter.iamClient, claims); err != nil {
-				logrus.Warn(err)
-				req.SetAttribute(ClaimsAttribute, nil)
-				chain.ProcessFilter(req, resp)
-				return
-			}
-		}
+// coreFilter returns the core.Filter validating the tokens with the verifier of the filter and its options.
+func (filter *Filter) coreFilter(allowEmptySubdomain bool) *core.Filter {
+	return core.NewFilter(&verifier{filter: filter, allowEmptySubdomain: allowEmptySubdomain}).
+		WithPublicAuthMode(filter.options.PublicAuthMode).
+		WithVerificationObserver(filter.options.VerificationObserver)
+}
 
-		chain.ProcessFilter(req, resp)
+// coreOptions adapts opts to the claims of the core.Filter.
+func (filter *Filter) coreOptions(opts []FilterOption) []core.FilterOption {
+	coreOpts := make([]core.FilterOption, 0, len(opts))
+	for _, opt := range opts {
+		coreOpts = append(coreOpts, func(req *restful.Request, claims core.Claims) error {
+			return opt(req, filter.iamClient, claims.Raw().(*iam.JWTClaims))
+		})
 	}
+
+	return coreOpts
 }
 
 // RetrieveJWTClaims is a convenience function to retrieve JWT claims
@@ -468,13 +389,23 @@ func WithoutBannedTopics(bannedTopics []string) FilterOption {
 	}
 }
 
-func validateSubdomainAgainstNamespace(host string, namespace string, excludedNamespaces []string) bool {
-	part := strings.Split(host, ".")
-	if len(part) < 3 {
+// hostNamespace returns the namespace of host mapped by hostMapping, or the subdomain of host when hostMapping
+// doesn't map it, so a mapping table only adds hosts to the validation.
+func hostNamespace(host string, hostMapping *hostmap.Table) (string, bool) {
+	if namespace, ok := hostMapping.Namespace(host); ok {
+		return namespace, true
+	}
+	return core.Subdomain(hostmap.NormalizeHost(host))
+}
+
+// validateSubdomainAgainstNamespace checks the namespace of host, see hostNamespace, against the claims namespace.
+// Hosts without namespace are not checked.
+func validateSubdomainAgainstNamespace(host string, namespace string, excludedNamespaces []string, hostMapping *hostmap.Table) bool {
+	subdomain, ok := hostNamespace(host, hostMapping)
+	if !ok {
 		// url with subdomain should have at least 3 part, e.g. foo.example.com, otherwise we should not check it
 		return true
 	}
-	subdomain := part[0]
 	for _, excludedNS := range excludedNamespaces {
 		if strings.ToLower(excludedNS) == strings.ToLower(namespace) {
 			return true
@@ -486,36 +417,6 @@ func validateSubdomainAgainstNamespace(host string, namespace string, excludedNa
 	return false
 }
 
-func getHost(req *http.Request) string {
-	if !req.URL.IsAbs() {
-		host := req.Host
-		if i := strings.Index(host, ":"); i != -1 {
-			host = host[:i]
-		}
-		return host
-	}
-	return req.URL.Host
-}
-
-// parseAccessToken is used to read token from Authorization Header or Cookie.
-// it will return the token value and token from.
-func parseAccessToken(request *restful.Request) (string, string, error) {
-	authorization := request.HeaderParameter("Authorization")
-	if strings.HasPrefix(strings.ToLower(authorization), "bearer ") {
-		if token := authorization[len("bearer "):]; token != "" {
-			return token, tokenFromHeader, nil
-		}
-	}
-
-	for _, cookie := range request.Request.Cookies() {
-		if cookie.Name == accessTokenCookieKey && cookie.Value != "" {
-			return cookie.Value, tokenFromCookie, nil
-		}
-	}
-
-	return "", "", errors.New("token not provided in request header")
-}
-
 // validateRefererHeader is used validate the referer header against client's redirectURIs.
 // we're not using Origin header since it will null for GET request.
 func (filter *Filter) validateRefererHeader(request *restful.Request, claims *iam.JWTClaims, allowEmptySubdomain bool) bool {
@@ -531,7 +432,11 @@ func (filter *Filter) validateRefererHeader(request *restful.Request, claims *ia
 		if err != nil {
 			return false
 		}
-		if !strings.HasPrefix(refererURL.Host, strings.ToLower(claims.Namespace)) {
+		if filter.options.HostMapping == nil {
+			if !strings.HasPrefix(refererURL.Host, strings.ToLower(claims.Namespace)) {
+				return false
+			}
+		} else if namespace, ok := hostNamespace(refererURL.Host, filter.options.HostMapping); !ok || !strings.EqualFold(namespace, claims.Namespace) {
 			return false
 		}
 	}
@@ -540,27 +445,9 @@ func (filter *Filter) validateRefererHeader(request *restful.Request, claims *ia
 		return true
 	}
 
-	if referer != "" {
-		refererDomain := util.GetDomain(referer)
-		clientRedirectURIs := strings.Split(clientInfo.RedirectURI, ",")
-		for _, redirectURI := range clientRedirectURIs {
-			if filter.options.AllowSubdomainMatchRefererHeaderValidation {
-				if validateRefererWithoutSubdomain(referer, redirectURI) {
-					return true
-				}
-			} else {
-				redirectURIDomain := util.GetDomain(redirectURI)
-				if filter.options.StrictRefererHeaderValidation {
-					if refererDomain == redirectURIDomain && strings.HasPrefix(referer, redirectURI) {
-						return true
-					}
-				} else {
-					if refererDomain == redirectURIDomain {
-						return true
-					}
-				}
-			}
-		}
+	if core.ValidateRefererAgainstRedirectURIs(referer, clientInfo.RedirectURI,
+		filter.options.StrictRefererHeaderValidation, filter.options.AllowSubdomainMatchRefererHeaderValidation) {
+		return true
 	}
 
 	logrus.Warnf("request has invalid referer header. referer header: %s. client redirect uri: %s",
@@ -568,132 +455,21 @@ func (filter *Filter) validateRefererHeader(request *restful.Request, claims *ia
 	return false
 }
 
-func validateRefererWithoutSubdomain(refererHeader string, clientRedirectURI string) bool {
-	refererURL, err := url.Parse(refererHeader)
-	if err != nil {
-		return false
-	}
-
-	clientRedirectURL, err := url.Parse(clientRedirectURI)
-	if err != nil {
-		return false
-	}
-
-	if refererURL.Scheme != clientRedirectURL.Scheme {
-		return false
-	}
-
-	// remove the ".www"
-	clientRedirectHost := strings.Replace(clientRedirectURL.Host, "www.", "", 1)
-
-	if strings.HasSuffix(refererURL.Host, clientRedirectHost) {
-		// check the character after the redirectUri string in referer string,
-		// if contains [a-zA-Z] character, then it's not a valid domain
-		// e.g.
-		// redirectUri host: accelbyte.io
-		// referer host: mygame.evilaccelbyte.io
-		if len(refererURL.Host) > len(clientRedirectHost) {
-			if refererURL.Host[len(refererURL.Host)-len(clientRedirectHost)-1] != '.' {
-				return false
-			}
-		}
-		return true
-	}
-
-	return false
-}
-
-func logIfErr(err error) {
-	if err != nil {
-		logrus.Error(err)
-	}
-}
-
 func respondError(httpStatus, errorCode int, errorMessage string) restful.ServiceError {
-	messageByte, err := json.Marshal(ErrorResponse{
-		ErrorCode:    errorCode,
-		ErrorMessage: errorMessage,
-	})
-	if err != nil {
-		errMsgByte, _ := json.Marshal(ErrorResponse{
-			ErrorCode:    InternalServerError,
-			ErrorMessage: "unable to parse error message : " + err.Error(),
-		})
-
-		return restful.ServiceError{
-			Code:    http.StatusInternalServerError,
-			Message: string(errMsgByte),
-		}
-	}
-
-	return restful.ServiceError{
-		Code:    httpStatus,
-		Message: string(messageByte),
-	}
+	return core.RespondError(httpStatus, errorCode, errorMessage)
 }
 
 func respondErrorWithRequiredPermission(httpStatus, errorCode int, errorMessage string, requiredPermission Permission) restful.ServiceError {
-	messageByte, err := json.Marshal(ErrorResponse{
-		ErrorCode:          errorCode,
-		ErrorMessage:       errorMessage,
-		RequiredPermission: &requiredPermission,
-	})
-	if err != nil {
-		errMsgByte, _ := json.Marshal(ErrorResponse{
-			ErrorCode:    InternalServerError,
-			ErrorMessage: "unable to parse error message : " + err.Error(),
-		})
-
-		return restful.ServiceError{
-			Code:    http.StatusInternalServerError,
-			Message: string(errMsgByte),
-		}
-	}
-
-	return restful.ServiceError{
-		Code:    httpStatus,
-		Message: string(messageByte),
-	}
+	return core.RespondErrorWithRequiredPermission(httpStatus, errorCode, errorMessage, requiredPermission)
 }
 
 func init() {
-	DevStackTraceable = true // activate verbose insufficient error message in non-prod environment
-	realmName, realmNameExists := os.LookupEnv("REALM_NAME")
-	if !realmNameExists {
-		DevStackTraceable = false
-		return
-	}
-
-	realmLive, realmLiveExists := os.LookupEnv("REALM_LIVE")
-	if !realmLiveExists {
-		realmLive = constant.DefaultRealmLive
-	}
-
-	realmLives := strings.Split(realmLive, ",")
-	for _, rl := range realmLives {
-		if realmName == rl {
-			DevStackTraceable = false
-			return
-		}
-	}
+	DevStackTraceable = core.DevStackTraceableFromEnv() // activate verbose insufficient error message in non-prod environment
 }
 
 // ActionConverter convert IAM action bit to human-readable
 func ActionConverter(action int) string {
-	var ActionStr string
-	switch action {
-	case iam.ActionRead:
-		ActionStr = constant.PermissionRead
-	case iam.ActionCreate:
-		ActionStr = constant.PermissionCreate
-	case iam.ActionUpdate:
-		ActionStr = constant.PermissionUpdate
-	case iam.ActionDelete:
-		ActionStr = constant.PermissionDelete
-	default:
-		return ""
-	}
-	return ActionStr
+	return core.ActionConverter(action)
 }
 
 // WithValidSubscription filters request from a user with verified subscription.
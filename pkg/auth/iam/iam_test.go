@@ -17,13 +17,16 @@ package iam
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
 	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/hostmap"
 	"github.com/AccelByte/iam-go-sdk/v2"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/stretchr/testify/assert"
@@ -1029,7 +1032,7 @@ func TestGetHost(t *testing.T) {
 				Host: testcase.requestHost,
 				URL:  &url.URL{Host: testcase.URLHost, Scheme: testcase.scheme},
 			}
-			assert.Equal(t, testcase.expected, getHost(req))
+			assert.Equal(t, testcase.expected, core.GetHost(req))
 		})
 	}
 }
@@ -1071,6 +1074,103 @@ func TestFilterInitializationOptionsFromEnv_SubdomainValidationExcludedNamespace
 	assert.Empty(t, options.SubdomainValidationExcludedNamespaces)
 }
 
+func TestFilterInitializationOptionsFromEnv_PublicAuthMode(t *testing.T) {
+	os.Setenv("PUBLIC_AUTH_MODE", "reject")
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, core.PublicAuthRejectInvalidToken, options.PublicAuthMode)
+
+	os.Setenv("PUBLIC_AUTH_MODE", "unknown")
+	options = FilterInitializationOptionsFromEnv()
+	assert.Equal(t, core.PublicAuthAnonymous, options.PublicAuthMode)
+	os.Unsetenv("PUBLIC_AUTH_MODE")
+}
+
+func TestFilterInitializationOptionsFromEnv_SubdomainValidationSuffixRules(t *testing.T) {
+	os.Setenv("SUBDOMAIN_VALIDATION_SUFFIX_RULES", "dev.accelbyte.io,prod.example.io:-1")
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, []hostmap.SuffixRule{{Suffix: "dev.accelbyte.io"}, {Suffix: "prod.example.io", LabelIndex: -1}}, options.HostMapping.Suffixes)
+
+	os.Setenv("SUBDOMAIN_VALIDATION_SUFFIX_RULES", "dev.accelbyte.io:first")
+	options = FilterInitializationOptionsFromEnv()
+	assert.Nil(t, options.HostMapping)
+	os.Unsetenv("SUBDOMAIN_VALIDATION_SUFFIX_RULES")
+}
+
+// nolint:paralleltest
+func TestValidateSubdomainAgainstNamespace_HostMapping(t *testing.T) {
+	hostMapping := &hostmap.Table{
+		Hosts:    hostmap.StaticRegistry{"play.mygame.com": "mygame"},
+		Suffixes: []hostmap.SuffixRule{{Suffix: "dev.accelbyte.io"}},
+	}
+
+	testcases := []struct {
+		name        string
+		host        string
+		namespace   string
+		hostMapping *hostmap.Table
+		valid       bool
+	}{
+		{name: "legacy_subdomain", host: "mygame.accelbyte.io", namespace: "mygame", valid: true},
+		{name: "legacy_multi_level_subdomain", host: "mygame.dev.accelbyte.io", namespace: "mygame", valid: true},
+		{name: "legacy_wrong_subdomain", host: "other.accelbyte.io", namespace: "mygame", valid: false},
+		{name: "legacy_vanity_domain", host: "play.mygame.com", namespace: "mygame", valid: false},
+		{name: "multi_level_subdomain", host: "mygame.dev.accelbyte.io", namespace: "mygame", hostMapping: hostMapping, valid: true},
+		{name: "wrong_multi_level_subdomain", host: "other.dev.accelbyte.io", namespace: "mygame", hostMapping: hostMapping, valid: false},
+		{name: "vanity_domain", host: "play.mygame.com", namespace: "mygame", hostMapping: hostMapping, valid: true},
+		{name: "wrong_vanity_domain", host: "play.mygame.com", namespace: "other", hostMapping: hostMapping, valid: false},
+		{name: "unmapped_host", host: "other.example.com", namespace: "mygame", hostMapping: hostMapping, valid: false},
+		{name: "unmapped_subdomain", host: "mygame.example.com", namespace: "mygame", hostMapping: hostMapping, valid: true},
+		{name: "unmapped_host_without_subdomain", host: "example.com", namespace: "mygame", hostMapping: hostMapping, valid: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.valid, validateSubdomainAgainstNamespace(testcase.host, testcase.namespace, nil, testcase.hostMapping))
+		})
+	}
+}
+
+// nolint:paralleltest
+func TestValidateRefererHeaderWithSubdomain_HostMapping(t *testing.T) {
+	iamClient := &iam.MockClient{
+		Healthy:     true,
+		RedirectURI: "https://example.com,https://mygame.com",
+	}
+	filter := NewFilterWithOptions(iamClient, &FilterInitializationOptions{
+		AllowSubdomainMatchRefererHeaderValidation: true,
+		SubdomainValidationEnabled:                 true,
+		HostMapping:                                &hostmap.Table{Hosts: hostmap.StaticRegistry{"play.mygame.com": "mock", "play.other.com": "other"}},
+	})
+
+	testcases := []struct {
+		name          string
+		refererHeader string
+		allowed       bool
+	}{
+		{name: "vanity_domain", refererHeader: "https://play.mygame.com", allowed: true},
+		{name: "vanity_domain_of_other_namespace", refererHeader: "https://play.other.com", allowed: false},
+		{name: "unmapped_subdomain", refererHeader: "https://mock.example.com", allowed: true},
+		{name: "unmapped_subdomain_of_other_namespace", refererHeader: "https://mockother.example.com", allowed: false},
+		{name: "unmapped_host_without_subdomain", refererHeader: "https://mygame.com", allowed: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			userTokenClaims, _ := filter.iamClient.ValidateAndParseClaims("dummyToken")
+
+			request := &restful.Request{
+				Request: &http.Request{
+					Header: map[string][]string{
+						constant.Referer: {testcase.refererHeader},
+					},
+				},
+			}
+
+			assert.Equal(t, testcase.allowed, filter.validateRefererHeader(request, userTokenClaims, false))
+		})
+	}
+}
+
 func TestWithoutBannedTopics(t *testing.T) {
 	timeNow := time.Now().UTC()
 	futureBanTime := timeNow.Add(24 * time.Hour)
@@ -1466,3 +1566,90 @@ func TestWithValidSubscription_Failed(t *testing.T) {
 		})
 	}
 }
+
+// nolint:paralleltest
+func TestVerifier_ValidatesCookieReferer(t *testing.T) {
+	iamClient := &iam.MockClient{
+		Healthy:     true,
+		RedirectURI: "https://www.example.com",
+	}
+	filter := core.NewFilter(NewVerifier(iamClient))
+
+	for referer, allowed := range map[string]bool{
+		"https://www.example.com/page": true,
+		"https://www.example.net/page": false,
+		"":                             false,
+	} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		httpReq.AddCookie(&http.Cookie{Name: core.AccessTokenCookieKey, Value: "dummyToken"})
+		if referer != "" {
+			httpReq.Header.Set(constant.Referer, referer)
+		}
+
+		called := false
+		chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+		filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), chain)
+		assert.Equal(t, allowed, called, referer)
+	}
+}
+
+// nolint:paralleltest
+func TestVerifier_ValidatesSubdomain(t *testing.T) {
+	filter := NewFilterWithOptions(&iam.MockClient{Healthy: true}, &FilterInitializationOptions{
+		SubdomainValidationEnabled: true,
+	})
+
+	for _, tc := range []struct {
+		filter restful.FilterFunction
+		host   string
+		status int
+	}{
+		{core.NewFilter(filter.Verifier()).Auth(), "mock.example.com", http.StatusOK},
+		{core.NewFilter(filter.Verifier()).Auth(), "other.example.com", http.StatusNotFound},
+		{filter.Auth(), "other.example.com", http.StatusNotFound},
+		{filter.AuthAllowEmptySubdomain(), "other.example.com", http.StatusOK},
+	} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		httpReq.Host = tc.host
+		httpReq.Header.Set("Authorization", "Bearer dummyToken")
+		recorder := httptest.NewRecorder()
+
+		chain := &restful.FilterChain{Target: func(_ *restful.Request, resp *restful.Response) { resp.WriteHeader(http.StatusOK) }}
+		tc.filter(restful.NewRequest(httpReq), restful.NewResponse(recorder), chain)
+		assert.Equal(t, tc.status, recorder.Code, tc.host)
+	}
+}
+
+// refreshingClient is a MockClient whose keys only know the token key ID after a refresh.
+type refreshingClient struct {
+	*iam.MockClient
+	refreshed int
+}
+
+func (c *refreshingClient) ValidateAndParseClaims(accessToken string, opts ...iam.Option) (*iam.JWTClaims, error) {
+	if c.refreshed == 0 || accessToken == "rotatedAgainToken" {
+		return nil, fmt.Errorf("validateJWT: invalid key: getPublicKey: public key doesn't exist")
+	}
+	return c.MockClient.ValidateAndParseClaims(accessToken, opts...)
+}
+
+func (c *refreshingClient) RefreshKeys() error {
+	c.refreshed++
+	return nil
+}
+
+// nolint:paralleltest
+func TestVerifier_RefreshKeysOnUnknownKeyID(t *testing.T) {
+	client := &refreshingClient{MockClient: &iam.MockClient{Healthy: true}}
+	filter := core.NewFilter(NewFilterWithOptions(client, &FilterInitializationOptions{KeyRefreshInterval: time.Hour}).Verifier())
+
+	claims, err := filter.Verify("dummyToken")
+	assert.NoError(t, err)
+	assert.NotNil(t, claims)
+	assert.Equal(t, 1, client.refreshed)
+
+	// the keys are refreshed at most once per KeyRefreshInterval
+	_, err = filter.Verify("rotatedAgainToken")
+	assert.Equal(t, core.VerificationFailureUnknownKeyID, core.ClassifyVerificationError(err))
+	assert.Equal(t, 1, client.refreshed)
+}
@@ -14,15 +14,31 @@
 
 package ic
 
+import "github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
+
 const (
-	// Global Error Codes
-	InternalServerError     = 20000
-	UnauthorizedAccess      = 20001
-	ForbiddenAccess         = 20002
-	TokenIsExpired          = 20003
-	InsufficientPermissions = 20004
-	InsufficientScope       = 20005
-	TokenIsNotUserToken     = 20006
+	// Global Error Codes, shared with the iam package
+	InternalServerError     = core.InternalServerError
+	UnauthorizedAccess      = core.UnauthorizedAccess
+	ForbiddenAccess         = core.ForbiddenAccess
+	TokenIsExpired          = core.TokenIsExpired
+	InsufficientPermissions = core.InsufficientPermissions
+	InsufficientScope       = core.InsufficientScope
+	TokenIsNotUserToken     = core.TokenIsNotUserToken
+	InvalidAudience         = core.InvalidAudience
+	InvalidRefererHeader    = core.InvalidRefererHeader
+	TokenIsNotYetValid      = core.TokenIsNotYetValid
+	InvalidTokenSignature   = core.InvalidTokenSignature
+	UnknownTokenSigningKey  = core.UnknownTokenSigningKey
+	TokenIsRevoked          = core.TokenIsRevoked
+	MalformedToken          = core.MalformedToken
+	SubdomainMismatch       = core.SubdomainMismatch
+)
+
+const (
+	// IC specific Error Codes
+	NotOrganizationMember     = 20060
+	InsufficientProjectAccess = 20061
 )
 
 var ErrorCodeMapping = map[int]string{
@@ -34,4 +50,15 @@ var ErrorCodeMapping = map[int]string{
 	InsufficientScope:       "insufficient scope",
 	TokenIsNotUserToken:     "token is not user token",
 	TokenIsExpired:          "token is expired",
+	InvalidAudience:         "invalid audience",
+	InvalidRefererHeader:    "invalid referer header",
+	TokenIsNotYetValid:      "token is not yet valid",
+	InvalidTokenSignature:   "invalid token signature",
+	UnknownTokenSigningKey:  "unknown token signing key",
+	TokenIsRevoked:          "token is revoked",
+	MalformedToken:          "malformed token",
+	SubdomainMismatch:       "subdomain mismatch",
+	// IC specific Error Codes
+	NotOrganizationMember:     "user is not a member of the organization",
+	InsufficientProjectAccess: "insufficient project access",
 }
@@ -15,15 +15,17 @@
 package ic
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/AccelByte/ic-go-sdk"
 	"github.com/emicklei/go-restful/v3"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
 	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
 	"github.com/sirupsen/logrus"
 )
@@ -31,10 +33,6 @@ import (
 const (
 	// ClaimsAttribute is the key for JWT claims stored in the request
 	ClaimsAttribute = "ICJWTClaims"
-
-	accessTokenCookieKey = "access_token"
-	tokenFromCookie      = "cookie"
-	tokenFromHeader      = "header"
 )
 
 var DevStackTraceable bool
@@ -44,97 +42,134 @@ type FilterOption func(req *restful.Request, icClient ic.Client, claims *ic.JWTC
 
 // FilterInitializationOptions hold options for Filter during initialization
 type FilterInitializationOptions struct {
+	StrictRefererHeaderValidation              bool                      // Enable full path check of redirect uri in referer header validation
+	AllowSubdomainMatchRefererHeaderValidation bool                      // Allow checking with subdomain
+	SubdomainValidationEnabled                 bool                      // Enable subdomain validation. When it is true, it will match the subdomain in the request url against the token's organizations.
+	SubdomainValidationExcludedOrganizations   []string                  // List of organization IDs to be excluded for subdomain validation.
+	SubdomainOrganizationMapping               map[string]string         // Map of subdomain to organization ID, for organizations whose subdomain is not their ID. Unmapped subdomains are compared to the organization ID as is.
+	PublicAuthMode                             core.PublicAuthMode       // How PublicAuth handles an invalid or expired token. Default treats the caller as anonymous.
+	VerificationObserver                       core.VerificationObserver // Notified of every rejected token, e.g. to count them in a metric labeled by failure.
+	KeyRefreshInterval                         time.Duration             // Minimum time between two key refreshes forced by an unknown key ID, when the client implements core.KeyRefresher. Default core.DefaultKeyRefreshInterval.
 }
 
 // Filter handles auth using filter
 type Filter struct {
-	icClient ic.Client
-	options  *FilterInitializationOptions
+	icClient        ic.Client
+	options         *FilterInitializationOptions
+	keyRefreshGuard *core.KeyRefreshGuard
 }
 
 // ErrorResponse is the generic structure for communicating errors from a REST endpoint.
-type ErrorResponse struct {
-	ErrorCode    int    `json:"errorCode"`
-	ErrorMessage string `json:"errorMessage"`
-}
+type ErrorResponse = core.ErrorResponse
+
+// Permission is the required permission reported in ErrorResponse
+type Permission = core.Permission
 
 // NewFilter creates new Filter instance
 func NewFilter(client ic.Client) *Filter {
-	return &Filter{icClient: client}
+	return NewFilterWithOptions(client, nil)
 }
 
-// Auth returns a filter that filters request with valid access token in auth header or cookie
-// The token's claims will be passed in the request.attributes["ICJWTClaims"] = *ic.JWTClaims{}
-// This filter is expandable through FilterOption parameter
+// NewFilterWithOptions creates new Filter instance with Options
 // Example:
-// ic.Auth(
-//
-//	WithValidUser(),
-//	WithPermission("ADMIN"),
 //
-// )
-func (filter *Filter) Auth(opts ...FilterOption) restful.FilterFunction {
-	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-		token, _, err := parseAccessToken(req)
-		if err != nil {
-			logrus.Warn("unauthorized access: ", err)
-			logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-				ErrorCode:    UnauthorizedAccess,
-				ErrorMessage: ErrorCodeMapping[UnauthorizedAccess],
-			}, restful.MIME_JSON))
+//	ic.NewFilterWithOptions(icClient, &FilterInitializationOptions{
+//		AllowSubdomainMatchRefererHeaderValidation: true,
+//		SubdomainValidationEnabled: true,
+//		SubdomainOrganizationMapping: map[string]string{"studio": "6b2f0a..."},
+//	})
+func NewFilterWithOptions(client ic.Client, options *FilterInitializationOptions) *Filter {
+	if options == nil {
+		options = &FilterInitializationOptions{}
+	}
+	return &Filter{
+		icClient:        client,
+		options:         options,
+		keyRefreshGuard: &core.KeyRefreshGuard{Interval: options.KeyRefreshInterval},
+	}
+}
 
-			return
-		}
+// FilterInitializationOptionsFromEnv creates FilterInitializationOptions from environment variables:
+//   - SUBDOMAIN_VALIDATION_ENABLED: enables subdomain validation and subdomain match referer validation
+//   - SUBDOMAIN_VALIDATION_EXCLUDED_ORGANIZATIONS: comma separated organization IDs excluded from subdomain validation
+//   - SUBDOMAIN_ORGANIZATION_MAPPING: comma separated subdomain:organizationId pairs
+func FilterInitializationOptionsFromEnv() *FilterInitializationOptions {
+	options := &FilterInitializationOptions{}
 
-		claims, err := filter.icClient.ValidateAndParseClaims(token)
+	if s, exists := os.LookupEnv("SUBDOMAIN_VALIDATION_ENABLED"); exists {
+		value, err := strconv.ParseBool(s)
 		if err != nil {
-			logrus.Warn("unauthorized access: ", err)
-			if err.Error() == ErrorCodeMapping[TokenIsExpired] {
-				logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-					ErrorCode:    TokenIsExpired,
-					ErrorMessage: ErrorCodeMapping[TokenIsExpired],
-				}, restful.MIME_JSON))
-				return
-			}
-			logIfErr(resp.WriteHeaderAndJson(http.StatusUnauthorized, ErrorResponse{
-				ErrorCode:    UnauthorizedAccess,
-				ErrorMessage: ErrorCodeMapping[UnauthorizedAccess],
-			}, restful.MIME_JSON))
-			return
+			logrus.Errorf("Parse SUBDOMAIN_VALIDATION_ENABLED env error: %v", err)
 		}
+		if value {
+			options.AllowSubdomainMatchRefererHeaderValidation = true
+			options.SubdomainValidationEnabled = true
+		}
+	}
 
-		req.SetAttribute(ClaimsAttribute, claims)
-		for _, opt := range opts {
-			if err = opt(req, filter.icClient, claims); err != nil {
-				if svcErr, ok := err.(restful.ServiceError); ok {
-					logrus.Warn(svcErr.Message)
-
-					var respErr ErrorResponse
-
-					err = json.Unmarshal([]byte(svcErr.Message), &respErr)
-					if err == nil {
-						logIfErr(resp.WriteHeaderAndJson(svcErr.Code, respErr, restful.MIME_JSON))
-					} else {
-						logIfErr(resp.WriteErrorString(svcErr.Code, svcErr.Message))
-					}
+	if s, exists := os.LookupEnv("SUBDOMAIN_VALIDATION_EXCLUDED_ORGANIZATIONS"); exists {
+		s = strings.TrimSpace(s)
+		s = strings.Trim(s, ",")
+		options.SubdomainValidationExcludedOrganizations = strings.Split(s, ",")
+	}
 
-					return
+	if s, exists := os.LookupEnv("SUBDOMAIN_ORGANIZATION_MAPPING"); exists {
+		options.SubdomainOrganizationMapping = make(map[string]string)
+		for _, pair := range strings.Split(s, ",") {
+			subdomain, organizationID, found := strings.Cut(strings.TrimSpace(pair), ":")
+			if !found || subdomain == "" || organizationID == "" {
+				if pair != "" {
+					logrus.Errorf("Parse SUBDOMAIN_ORGANIZATION_MAPPING env error: invalid pair %q", pair)
 				}
-
-				logrus.Warn(err)
-				logIfErr(resp.WriteErrorString(http.StatusUnauthorized, err.Error()))
-
-				return
+				continue
 			}
+			options.SubdomainOrganizationMapping[strings.ToLower(subdomain)] = organizationID
 		}
+	}
 
-		chain.ProcessFilter(req, resp)
+	if s, exists := os.LookupEnv("PUBLIC_AUTH_MODE"); exists {
+		mode, err := core.ParsePublicAuthMode(s)
+		if err != nil {
+			logrus.Errorf("Parse PUBLIC_AUTH_MODE env error: %v", err)
+		}
+		options.PublicAuthMode = mode
 	}
+
+	return options
+}
+
+// Auth returns a filter that filters request with valid access token in auth header or cookie
+// The token's claims will be passed in the request.attributes["ICJWTClaims"] = *ic.JWTClaims{}
+// This filter is expandable through FilterOption parameter
+// Example:
+// ic.Auth(
+//
+//	WithValidUser(),
+//	WithPermission("ADMIN"),
+//
+// )
+func (filter *Filter) Auth(opts ...FilterOption) restful.FilterFunction {
+	return filter.authFunc(false, opts...)
+}
+
+// AuthAllowEmptySubdomain returns a filter that filters request with valid access token in auth header or cookie
+// The difference with Auth() is this function will also allow request without subdomain
+//
+// The token's claims will be passed in the request.attributes["ICJWTClaims"] = *ic.JWTClaims{}
+// This filter is expandable through FilterOption parameter
+func (filter *Filter) AuthAllowEmptySubdomain(opts ...FilterOption) restful.FilterFunction {
+	return filter.authFunc(true, opts...)
+}
+
+func (filter *Filter) authFunc(allowEmptySubdomain bool, opts ...FilterOption) restful.FilterFunction {
+	return filter.coreFilter(allowEmptySubdomain).Auth(filter.coreOptions(opts)...)
 }
 
 // PublicAuth returns a filter that allow unauthenticated request and request with valid access token in auth header or cookie
 // If request has access token, the token's claims will be passed in the request.attributes["ICJWTClaims"] = *ic.JWTClaims{}
-// If request has invalid access token, then request treated as public access without claims
+// If request has invalid access token, it is handled according to FilterInitializationOptions.PublicAuthMode,
+// by default the request is treated as public access without claims
+// When the request ends up without claims, the reason can be read with core.RetrieveClaimsAbsenceReason
 // This filter is expandable through FilterOption parameter
 // Example:
 // ic.PublicAuth(
@@ -144,32 +179,26 @@ func (filter *Filter) Auth(opts ...FilterOption) restful.FilterFunction {
 //
 // )
 func (filter *Filter) PublicAuth(opts ...FilterOption) restful.FilterFunction {
-	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-		token, _, err := parseAccessToken(req)
-		if err != nil {
-			chain.ProcessFilter(req, resp)
-			return
-		}
-
-		claims, err := filter.icClient.ValidateAndParseClaims(token)
-		if err != nil {
-			logrus.Warn("unauthorized access for public endpoint: ", err)
-			chain.ProcessFilter(req, resp)
-			return
-		}
+	return filter.coreFilter(false).PublicAuth(filter.coreOptions(opts)...)
+}
 
-		req.SetAttribute(ClaimsAttribute, claims)
-		for _, opt := range opts {
-			if err = opt(req, filter.icClient, claims); err != nil {
-				logrus.Warn(err)
-				req.SetAttribute(ClaimsAttribute, nil)
-				chain.ProcessFilter(req, resp)
-				return
-			}
-		}
+// coreFilter returns the core.Filter validating the tokens with the verifier of the filter and its options.
+func (filter *Filter) coreFilter(allowEmptySubdomain bool) *core.Filter {
+	return core.NewFilter(&verifier{filter: filter, allowEmptySubdomain: allowEmptySubdomain}).
+		WithPublicAuthMode(filter.options.PublicAuthMode).
+		WithVerificationObserver(filter.options.VerificationObserver)
+}
 
-		chain.ProcessFilter(req, resp)
+// coreOptions adapts opts to the claims of the core.Filter.
+func (filter *Filter) coreOptions(opts []FilterOption) []core.FilterOption {
+	coreOpts := make([]core.FilterOption, 0, len(opts))
+	for _, opt := range opts {
+		coreOpts = append(coreOpts, func(req *restful.Request, claims core.Claims) error {
+			return opt(req, filter.icClient, claims.Raw().(*ic.JWTClaims))
+		})
 	}
+
+	return coreOpts
 }
 
 // RetrieveJWTClaims is a convenience function to retrieve JWT claims
@@ -213,98 +242,211 @@ func WithPermission(permission *ic.Permission) FilterOption {
 				permission.Resource, action)
 		}
 		if !valid {
-			return respondError(http.StatusForbidden, InsufficientPermissions,
-				"access forbidden: "+insufficientPermissionMessage)
+			return core.RespondErrorWithRequiredPermission(http.StatusForbidden, InsufficientPermissions,
+				"access forbidden: "+insufficientPermissionMessage, Permission{
+					Resource: permission.Resource,
+					Action:   permission.Action,
+				})
 		}
 
 		return nil
 	}
 }
 
-// parseAccessToken is used to read token from Authorization Header or Cookie.
-// it will return the token value and token from.
-func parseAccessToken(request *restful.Request) (string, string, error) {
-	authorization := request.HeaderParameter("Authorization")
-	if strings.HasPrefix(strings.ToLower(authorization), "bearer ") {
-		if token := authorization[len("bearer "):]; token != "" {
-			return token, tokenFromHeader, nil
+// WithValidScope filters request from a user with verified scope
+func WithValidScope(scope string) FilterOption {
+	return func(req *restful.Request, icClient ic.Client, claims *ic.JWTClaims) error {
+		if core.HasScope(claims.Scope, scope) {
+			return nil
 		}
+
+		insufficientScopeMessage := ErrorCodeMapping[InsufficientScope]
+		if DevStackTraceable {
+			insufficientScopeMessage = fmt.Sprintf("%s. Required scope: %s", insufficientScopeMessage,
+				scope)
+		}
+
+		return respondError(http.StatusForbidden, InsufficientScope,
+			"access forbidden: "+insufficientScopeMessage)
 	}
+}
 
-	for _, cookie := range request.Request.Cookies() {
-		if cookie.Name == accessTokenCookieKey && cookie.Value != "" {
-			return cookie.Value, tokenFromCookie, nil
+// WithValidAudience filters request from a token issued for the given audience,
+// usually the base URI of the service
+func WithValidAudience(audience string) FilterOption {
+	return func(req *restful.Request, icClient ic.Client, claims *ic.JWTClaims) error {
+		if claims.Audience.Contains(audience) {
+			return nil
 		}
+
+		return respondError(http.StatusForbidden, InvalidAudience,
+			"access forbidden: "+ErrorCodeMapping[InvalidAudience])
 	}
+}
+
+// WithOrganizationMembership filters request from a user belonging to the organization in the
+// {organizationId} path parameter. The token's organizations are its organizationId claim
+// and the organizations of its roles. Routes without {organizationId} are not checked.
+func WithOrganizationMembership() FilterOption {
+	return func(req *restful.Request, icClient ic.Client, claims *ic.JWTClaims) error {
+		pathOrganizationID := req.PathParameter("organizationId")
+		if pathOrganizationID == "" {
+			return nil
+		}
+
+		for _, organizationID := range Organizations(claims) {
+			if organizationID == pathOrganizationID {
+				return nil
+			}
+		}
 
-	return "", "", errors.New("token not provided in request header")
+		notMemberMessage := ErrorCodeMapping[NotOrganizationMember]
+		if DevStackTraceable {
+			notMemberMessage = fmt.Sprintf("%s. Required organization: %s", notMemberMessage, pathOrganizationID)
+		}
+
+		return respondError(http.StatusForbidden, NotOrganizationMember,
+			"access forbidden: "+notMemberMessage)
+	}
 }
 
-func logIfErr(err error) {
-	if err != nil {
-		logrus.Error(err)
+// WithProjectAccess filters request from a user having a role on the project in the {projectId} path parameter.
+// A role grants access when it is scoped to that project. When the route also has {organizationId},
+// the role must belong to that organization, and organization wide roles (no projectId) of that
+// organization grant access to all of its projects. Routes without {projectId} are not checked.
+func WithProjectAccess() FilterOption {
+	return func(req *restful.Request, icClient ic.Client, claims *ic.JWTClaims) error {
+		pathProjectID := req.PathParameter("projectId")
+		if pathProjectID == "" {
+			return nil
+		}
+		pathOrganizationID := req.PathParameter("organizationId")
+
+		for _, role := range claims.Roles {
+			if pathOrganizationID != "" && role.OrganizationID != pathOrganizationID {
+				continue
+			}
+			if role.ProjectID == pathProjectID {
+				return nil
+			}
+			if role.ProjectID == "" && pathOrganizationID != "" {
+				return nil
+			}
+		}
+
+		insufficientProjectAccessMessage := ErrorCodeMapping[InsufficientProjectAccess]
+		if DevStackTraceable {
+			insufficientProjectAccessMessage = fmt.Sprintf("%s. Required project: %s",
+				insufficientProjectAccessMessage, pathProjectID)
+		}
+
+		return respondError(http.StatusForbidden, InsufficientProjectAccess,
+			"access forbidden: "+insufficientProjectAccessMessage)
 	}
 }
 
-func respondError(httpStatus, errorCode int, errorMessage string) restful.ServiceError {
-	messageByte, err := json.Marshal(ErrorResponse{
-		ErrorCode:    errorCode,
-		ErrorMessage: errorMessage,
-	})
-	if err != nil {
-		errMsgByte, _ := json.Marshal(ErrorResponse{
-			ErrorCode:    InternalServerError,
-			ErrorMessage: "unable to parse error message : " + err.Error(),
-		})
+// Organizations returns the distinct organization IDs the token belongs to
+func Organizations(claims *ic.JWTClaims) []string {
+	if claims == nil {
+		return nil
+	}
 
-		return restful.ServiceError{
-			Code:    http.StatusInternalServerError,
-			Message: string(errMsgByte),
+	seen := make(map[string]bool)
+	organizations := make([]string, 0, len(claims.Roles)+1)
+	add := func(organizationID string) {
+		if organizationID != "" && !seen[organizationID] {
+			seen[organizationID] = true
+			organizations = append(organizations, organizationID)
 		}
 	}
 
-	return restful.ServiceError{
-		Code:    httpStatus,
-		Message: string(messageByte),
+	add(claims.OrganizationID)
+	for _, role := range claims.Roles {
+		add(role.OrganizationID)
 	}
+
+	return organizations
 }
 
-func init() {
-	DevStackTraceable = true // activate verbose insufficient error message in non-prod environment
-	realmName, realmNameExists := os.LookupEnv("REALM_NAME")
-	if !realmNameExists {
-		DevStackTraceable = false
-		return
+// organizationForSubdomain returns the organization ID served by the subdomain
+func (filter *Filter) organizationForSubdomain(subdomain string) string {
+	if organizationID, ok := filter.options.SubdomainOrganizationMapping[strings.ToLower(subdomain)]; ok {
+		return organizationID
+	}
+	return subdomain
+}
+
+// validateSubdomainAgainstOrganization checks that the subdomain of host belongs to one of the token's organizations.
+// Hosts without subdomain and tokens of excluded organizations are not checked.
+func (filter *Filter) validateSubdomainAgainstOrganization(host string, claims *ic.JWTClaims) bool {
+	subdomain, ok := core.Subdomain(host)
+	if !ok {
+		// url with subdomain should have at least 3 part, e.g. foo.example.com, otherwise we should not check it
+		return true
 	}
 
-	realmLive, realmLiveExists := os.LookupEnv("REALM_LIVE")
-	if !realmLiveExists {
-		realmLive = constant.DefaultRealmLive
+	organizations := Organizations(claims)
+	for _, excludedOrganization := range filter.options.SubdomainValidationExcludedOrganizations {
+		for _, organizationID := range organizations {
+			if strings.EqualFold(excludedOrganization, organizationID) {
+				return true
+			}
+		}
 	}
 
-	realmLives := strings.Split(realmLive, ",")
-	for _, rl := range realmLives {
-		if realmName == rl {
-			DevStackTraceable = false
-			return
+	subdomainOrganization := filter.organizationForSubdomain(subdomain)
+	for _, organizationID := range organizations {
+		if strings.EqualFold(subdomainOrganization, organizationID) {
+			return true
 		}
 	}
+
+	return false
+}
+
+// validateRefererHeader is used validate the referer header against client's redirectURIs.
+// we're not using Origin header since it will null for GET request.
+func (filter *Filter) validateRefererHeader(request *restful.Request, claims *ic.JWTClaims, allowEmptySubdomain bool) bool {
+	clientInfo, err := filter.icClient.GetClientInformation(claims.ClientID)
+	if err != nil {
+		logrus.Errorf("validate referer header error: %v", err.Error())
+		return false
+	}
+
+	referer := request.HeaderParameter(constant.Referer)
+	if filter.options.SubdomainValidationEnabled && referer != "" && !allowEmptySubdomain {
+		refererURL, err := url.Parse(referer)
+		if err != nil {
+			return false
+		}
+		if !filter.validateSubdomainAgainstOrganization(refererURL.Hostname(), claims) {
+			return false
+		}
+	}
+
+	if len(clientInfo.RedirectURI) == 0 {
+		return true
+	}
+
+	if core.ValidateRefererAgainstRedirectURIs(referer, clientInfo.RedirectURI,
+		filter.options.StrictRefererHeaderValidation, filter.options.AllowSubdomainMatchRefererHeaderValidation) {
+		return true
+	}
+
+	logrus.Warnf("request has invalid referer header. referer header: %s. client redirect uri: %s",
+		referer, clientInfo.RedirectURI)
+	return false
+}
+
+func respondError(httpStatus, errorCode int, errorMessage string) restful.ServiceError {
+	return core.RespondError(httpStatus, errorCode, errorMessage)
+}
+
+func init() {
+	DevStackTraceable = core.DevStackTraceableFromEnv() // activate verbose insufficient error message in non-prod environment
 }
 
 // ActionConverter convert IC action bit to human-readable
 func ActionConverter(action int) string {
-	var ActionStr string
-	switch action {
-	case ic.ActionRead:
-		ActionStr = constant.PermissionRead
-	case ic.ActionCreate:
-		ActionStr = constant.PermissionCreate
-	case ic.ActionUpdate:
-		ActionStr = constant.PermissionUpdate
-	case ic.ActionDelete:
-		ActionStr = constant.PermissionDelete
-	default:
-		return ""
-	}
-	return ActionStr
+	return core.ActionConverter(action)
 }
@@ -0,0 +1,515 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/AccelByte/go-jose/jwt"
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
+	"github.com/AccelByte/ic-go-sdk"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	claims            *ic.JWTClaims
+	permissionAllowed bool
+	redirectURI       string
+}
+
+func (m *mockClient) ClientToken() (string, error) { return "clientToken", nil }
+
+func (m *mockClient) ValidateAccessToken(accessToken string) (bool, error) {
+	return accessToken == "dummyToken", nil
+}
+
+func (m *mockClient) ValidateAndParseClaims(accessToken string) (*ic.JWTClaims, error) {
+	if accessToken != "dummyToken" {
+		return nil, errors.New("invalid token")
+	}
+	return m.claims, nil
+}
+
+func (m *mockClient) ValidatePermission(_ *ic.JWTClaims, _ ic.Permission, _ map[string]string) (bool, error) {
+	return m.permissionAllowed, nil
+}
+
+func (m *mockClient) GetRolePermissions(_ string) ([]ic.Permission, error) { return nil, nil }
+
+func (m *mockClient) GetClientInformation(_ string) (*ic.ClientInformation, error) {
+	return &ic.ClientInformation{RedirectURI: m.redirectURI}, nil
+}
+
+func newPathRequest(pathParameters map[string]string) *restful.Request {
+	req := restful.NewRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	for name, value := range pathParameters {
+		req.PathParameters()[name] = value
+	}
+	return req
+}
+
+func decodeServiceError(t *testing.T, err error) (restful.ServiceError, ErrorResponse) {
+	t.Helper()
+	svcErr, ok := err.(restful.ServiceError)
+	assert.True(t, ok)
+	var errResp ErrorResponse
+	assert.NoError(t, json.Unmarshal([]byte(svcErr.Message), &errResp))
+	return svcErr, errResp
+}
+
+// nolint:paralleltest
+func TestWithValidScope(t *testing.T) {
+	claims := &ic.JWTClaims{Scope: "account commerce"}
+
+	assert.NoError(t, WithValidScope("commerce")(newPathRequest(nil), &mockClient{}, claims))
+
+	err := WithValidScope("social")(newPathRequest(nil), &mockClient{}, claims)
+	svcErr, errResp := decodeServiceError(t, err)
+	assert.Equal(t, http.StatusForbidden, svcErr.Code)
+	assert.Equal(t, InsufficientScope, errResp.ErrorCode)
+}
+
+// nolint:paralleltest
+func TestWithValidAudience(t *testing.T) {
+	claims := &ic.JWTClaims{Claims: jwt.Claims{Audience: jwt.Audience{"https://ic.example.com"}}}
+
+	assert.NoError(t, WithValidAudience("https://ic.example.com")(newPathRequest(nil), &mockClient{}, claims))
+
+	err := WithValidAudience("https://other.example.com")(newPathRequest(nil), &mockClient{}, claims)
+	svcErr, errResp := decodeServiceError(t, err)
+	assert.Equal(t, http.StatusForbidden, svcErr.Code)
+	assert.Equal(t, InvalidAudience, errResp.ErrorCode)
+}
+
+// nolint:paralleltest
+func TestWithOrganizationMembership(t *testing.T) {
+	claims := &ic.JWTClaims{
+		OrganizationID: "org1",
+		Roles: []ic.ClaimRole{
+			{RoleID: "viewer", OrganizationID: "org2", ProjectID: "proj1"},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		params  map[string]string
+		allowed bool
+	}{
+		{name: "token organization", params: map[string]string{"organizationId": "org1"}, allowed: true},
+		{name: "role organization", params: map[string]string{"organizationId": "org2"}, allowed: true},
+		{name: "other organization", params: map[string]string{"organizationId": "org3"}, allowed: false},
+		{name: "no organization in path", params: nil, allowed: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := WithOrganizationMembership()(newPathRequest(testcase.params), &mockClient{}, claims)
+			if testcase.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			svcErr, errResp := decodeServiceError(t, err)
+			assert.Equal(t, http.StatusForbidden, svcErr.Code)
+			assert.Equal(t, NotOrganizationMember, errResp.ErrorCode)
+		})
+	}
+}
+
+// nolint:paralleltest
+func TestWithProjectAccess(t *testing.T) {
+	claims := &ic.JWTClaims{
+		OrganizationID: "org1",
+		Roles: []ic.ClaimRole{
+			{RoleID: "admin", OrganizationID: "org1"},
+			{RoleID: "viewer", OrganizationID: "org2", ProjectID: "proj2"},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		params  map[string]string
+		allowed bool
+	}{
+		{name: "project role", params: map[string]string{"organizationId": "org2", "projectId": "proj2"}, allowed: true},
+		{name: "project role without organization", params: map[string]string{"projectId": "proj2"}, allowed: true},
+		{name: "organization wide role", params: map[string]string{"organizationId": "org1", "projectId": "proj9"}, allowed: true},
+		{name: "project role of other organization", params: map[string]string{"organizationId": "org1", "projectId": "proj2"}, allowed: true},
+		{name: "other project", params: map[string]string{"organizationId": "org2", "projectId": "proj3"}, allowed: false},
+		{name: "organization wide role needs organization in path", params: map[string]string{"projectId": "proj9"}, allowed: false},
+		{name: "no project in path", params: map[string]string{"organizationId": "org3"}, allowed: true},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := WithProjectAccess()(newPathRequest(testcase.params), &mockClient{}, claims)
+			if testcase.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			svcErr, errResp := decodeServiceError(t, err)
+			assert.Equal(t, http.StatusForbidden, svcErr.Code)
+			assert.Equal(t, InsufficientProjectAccess, errResp.ErrorCode)
+		})
+	}
+}
+
+// nolint:paralleltest
+func TestWithPermission_RequiredPermission(t *testing.T) {
+	permission := &ic.Permission{Resource: "ADMIN:ORG:{organizationId}:PROJ:{projectId}:Info", Action: ic.ActionUpdate}
+
+	assert.NoError(t, WithPermission(permission)(newPathRequest(nil), &mockClient{permissionAllowed: true}, &ic.JWTClaims{}))
+
+	err := WithPermission(permission)(newPathRequest(nil), &mockClient{permissionAllowed: false}, &ic.JWTClaims{})
+	svcErr, errResp := decodeServiceError(t, err)
+	assert.Equal(t, http.StatusForbidden, svcErr.Code)
+	assert.Equal(t, InsufficientPermissions, errResp.ErrorCode)
+	assert.Equal(t, &Permission{Resource: permission.Resource, Action: permission.Action}, errResp.RequiredPermission)
+}
+
+// nolint:paralleltest
+func TestOrganizations(t *testing.T) {
+	claims := &ic.JWTClaims{
+		OrganizationID: "org1",
+		Roles: []ic.ClaimRole{
+			{RoleID: "a", OrganizationID: "org1"},
+			{RoleID: "b", OrganizationID: "org2"},
+			{RoleID: "c"},
+		},
+	}
+
+	assert.Equal(t, []string{"org1", "org2"}, Organizations(claims))
+	assert.Nil(t, Organizations(nil))
+}
+
+func newRefererRequest(referer string) *restful.Request {
+	return &restful.Request{
+		Request: &http.Request{
+			Header: map[string][]string{
+				constant.Referer: {referer},
+			},
+		},
+	}
+}
+
+// The referer test cases mirror the iam package ones, so both filters behave the same.
+// nolint:paralleltest
+func TestValidateRefererHeader_Parity(t *testing.T) {
+	claims := &ic.JWTClaims{OrganizationID: "mock", ClientID: "client"}
+
+	testcases := []struct {
+		name          string
+		redirectURI   string
+		options       *FilterInitializationOptions
+		refererHeader string
+		allowed       bool
+	}{
+		{name: "domain: normal referer", redirectURI: "https://www.example.com", refererHeader: "https://www.example.com", allowed: true},
+		{name: "domain: referer with path", redirectURI: "https://www.example.com", refererHeader: "https://www.example.com/path/path", allowed: true},
+		{name: "domain: wrong referer", redirectURI: "https://www.example.com", refererHeader: "https://www.example.net", allowed: false},
+		{name: "domain: wrong port", redirectURI: "https://www.example.com", refererHeader: "https://www.example.com:8080", allowed: false},
+		{name: "domain: subdomain", redirectURI: "https://www.example.com", refererHeader: "https://subdomain.example.com", allowed: false},
+		{name: "domain: extra wrong domain", redirectURI: "https://www.example.com", refererHeader: "https://www.example.com.something.net", allowed: false},
+		{name: "domain: empty referer", redirectURI: "https://www.example.com", refererHeader: "", allowed: false},
+		{name: "no redirect uri", redirectURI: "", refererHeader: "https://anything.net", allowed: true},
+		{name: "multiple: first", redirectURI: "https://www.example.com,https://www.example.io", refererHeader: "https://www.example.com", allowed: true},
+		{name: "multiple: second", redirectURI: "https://www.example.com,https://www.example.io", refererHeader: "https://www.example.io/path/path", allowed: true},
+		{name: "multiple: wrong", redirectURI: "https://www.example.com,https://www.example.io", refererHeader: "www.example.com", allowed: false},
+		{
+			name: "strict: prefix", redirectURI: "https://www.example.com/admin", refererHeader: "https://www.example.com/admin/path/path", allowed: true,
+			options: &FilterInitializationOptions{StrictRefererHeaderValidation: true},
+		},
+		{
+			name: "strict: other path", redirectURI: "https://www.example.com/admin", refererHeader: "https://www.example.com/path/path", allowed: false,
+			options: &FilterInitializationOptions{StrictRefererHeaderValidation: true},
+		},
+		{
+			name: "subdomain match: subdomain", redirectURI: "https://example.com", refererHeader: "https://subdomain.example.com", allowed: true,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true},
+		},
+		{
+			name: "subdomain match: www redirect uri", redirectURI: "https://www.example.com", refererHeader: "https://subdomain.example.com", allowed: true,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true},
+		},
+		{
+			name: "subdomain match: wrong scheme", redirectURI: "https://example.com", refererHeader: "http://example.com", allowed: false,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true},
+		},
+		{
+			name: "subdomain match: similar domain", redirectURI: "https://example.com", refererHeader: "https://examplewww.com", allowed: false,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true},
+		},
+		{
+			name: "subdomain validation: token organization", redirectURI: "https://example.com", refererHeader: "https://mock.example.com/admin/path", allowed: true,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true, SubdomainValidationEnabled: true},
+		},
+		{
+			name: "subdomain validation: other organization", redirectURI: "https://example.com", refererHeader: "https://subdomain.example.com", allowed: false,
+			options: &FilterInitializationOptions{AllowSubdomainMatchRefererHeaderValidation: true, SubdomainValidationEnabled: true},
+		},
+		{
+			name: "subdomain validation: mapped subdomain", redirectURI: "https://example.com", refererHeader: "https://studio.example.com", allowed: true,
+			options: &FilterInitializationOptions{
+				AllowSubdomainMatchRefererHeaderValidation: true,
+				SubdomainValidationEnabled:                 true,
+				SubdomainOrganizationMapping:               map[string]string{"studio": "mock"},
+			},
+		},
+		{
+			name: "subdomain validation: excluded organization", redirectURI: "https://example.com", refererHeader: "https://subdomain.example.com", allowed: true,
+			options: &FilterInitializationOptions{
+				AllowSubdomainMatchRefererHeaderValidation: true,
+				SubdomainValidationEnabled:                 true,
+				SubdomainValidationExcludedOrganizations:   []string{"mock"},
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			filter := NewFilterWithOptions(&mockClient{redirectURI: testcase.redirectURI}, testcase.options)
+
+			actual := filter.validateRefererHeader(newRefererRequest(testcase.refererHeader), claims, false)
+			assert.Equal(t, testcase.allowed, actual)
+		})
+	}
+}
+
+// nolint:paralleltest
+func TestAuth_CookieRefererValidation(t *testing.T) {
+	client := &mockClient{claims: &ic.JWTClaims{OrganizationID: "mock"}, redirectURI: "https://www.example.com"}
+	filter := NewFilter(client)
+
+	for referer, allowed := range map[string]bool{
+		"https://www.example.com/page": true,
+		"https://www.example.net/page": false,
+	} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		httpReq.AddCookie(&http.Cookie{Name: "access_token", Value: "dummyToken"})
+		httpReq.Header.Set(constant.Referer, referer)
+		recorder := httptest.NewRecorder()
+		called := false
+		chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+
+		filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(recorder), chain)
+
+		assert.Equal(t, allowed, called, referer)
+		if !allowed {
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code, referer)
+		}
+	}
+}
+
+// nolint:paralleltest
+func TestAuth_SubdomainValidation(t *testing.T) {
+	client := &mockClient{claims: &ic.JWTClaims{OrganizationID: "org-id"}}
+	filter := NewFilterWithOptions(client, &FilterInitializationOptions{
+		SubdomainValidationEnabled:   true,
+		SubdomainOrganizationMapping: map[string]string{"studio": "org-id"},
+	})
+
+	testcases := []struct {
+		host    string
+		allowed bool
+	}{
+		{host: "studio.example.com", allowed: true},
+		{host: "org-id.example.com", allowed: true},
+		{host: "other.example.com", allowed: false},
+		{host: "example.com", allowed: true},
+	}
+
+	for _, testcase := range testcases {
+		httpReq := httptest.NewRequest(http.MethodGet, "http://"+testcase.host+"/", nil)
+		httpReq.Header.Set("Authorization", "Bearer dummyToken")
+		recorder := httptest.NewRecorder()
+		called := false
+		chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+
+		filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(recorder), chain)
+
+		assert.Equal(t, testcase.allowed, called, testcase.host)
+		if !testcase.allowed {
+			assert.Equal(t, http.StatusNotFound, recorder.Code, testcase.host)
+		}
+
+		called = false
+		filter.AuthAllowEmptySubdomain()(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), chain)
+		assert.True(t, called, testcase.host)
+	}
+}
+
+func TestFilterInitializationOptionsFromEnv_SubdomainValidationEnabled(t *testing.T) {
+	os.Setenv("SUBDOMAIN_VALIDATION_ENABLED", "true")
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, true, options.AllowSubdomainMatchRefererHeaderValidation)
+	assert.Equal(t, true, options.SubdomainValidationEnabled)
+	os.Unsetenv("SUBDOMAIN_VALIDATION_ENABLED")
+}
+
+func TestFilterInitializationOptionsFromEnv_SubdomainValidationDisabled(t *testing.T) {
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, false, options.AllowSubdomainMatchRefererHeaderValidation)
+	assert.Equal(t, false, options.SubdomainValidationEnabled)
+	assert.Empty(t, options.SubdomainValidationExcludedOrganizations)
+	assert.Empty(t, options.SubdomainOrganizationMapping)
+}
+
+func TestFilterInitializationOptionsFromEnv_ExcludedOrganizations(t *testing.T) {
+	os.Setenv("SUBDOMAIN_VALIDATION_EXCLUDED_ORGANIZATIONS", "     org1,org2,org3,,,    ")
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, []string{"org1", "org2", "org3"}, options.SubdomainValidationExcludedOrganizations)
+	os.Unsetenv("SUBDOMAIN_VALIDATION_EXCLUDED_ORGANIZATIONS")
+}
+
+func TestFilterInitializationOptionsFromEnv_OrganizationMapping(t *testing.T) {
+	os.Setenv("SUBDOMAIN_ORGANIZATION_MAPPING", "Studio:org1, game:org2,invalid,")
+	options := FilterInitializationOptionsFromEnv()
+	assert.Equal(t, map[string]string{"studio": "org1", "game": "org2"}, options.SubdomainOrganizationMapping)
+	os.Unsetenv("SUBDOMAIN_ORGANIZATION_MAPPING")
+}
+
+// nolint:paralleltest
+func TestPublicAuth_Modes(t *testing.T) {
+	client := &mockClient{claims: &ic.JWTClaims{OrganizationID: "mock"}, redirectURI: "https://www.example.com"}
+
+	testcases := []struct {
+		name    string
+		mode    core.PublicAuthMode
+		request func() *http.Request
+		called  bool
+		reason  core.ClaimsAbsenceReason
+		status  int
+	}{
+		{
+			name: "anonymous: invalid token", mode: core.PublicAuthAnonymous, called: true, reason: core.ClaimsAbsenceInvalidToken, status: http.StatusOK,
+			request: func() *http.Request {
+				httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+				httpReq.Header.Set("Authorization", "Bearer badToken")
+				return httpReq
+			},
+		},
+		{
+			name: "reject: no token", mode: core.PublicAuthRejectInvalidToken, called: true, reason: core.ClaimsAbsenceNoToken, status: http.StatusOK,
+			request: func() *http.Request { return httptest.NewRequest(http.MethodGet, "/", nil) },
+		},
+		{
+			name: "reject: invalid token", mode: core.PublicAuthRejectInvalidToken, reason: core.ClaimsAbsenceInvalidToken, status: http.StatusUnauthorized,
+			request: func() *http.Request {
+				httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+				httpReq.Header.Set("Authorization", "Bearer badToken")
+				return httpReq
+			},
+		},
+		{
+			name: "reject: invalid referer", mode: core.PublicAuthRejectInvalidToken, reason: core.ClaimsAbsenceInvalidReferer, status: http.StatusUnauthorized,
+			request: func() *http.Request {
+				httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+				httpReq.AddCookie(&http.Cookie{Name: "access_token", Value: "dummyToken"})
+				httpReq.Header.Set(constant.Referer, "https://www.example.net")
+				return httpReq
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			filter := NewFilterWithOptions(client, &FilterInitializationOptions{PublicAuthMode: testcase.mode})
+			req := restful.NewRequest(testcase.request())
+			recorder := httptest.NewRecorder()
+			called := false
+			chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+
+			filter.PublicAuth()(req, restful.NewResponse(recorder), chain)
+
+			assert.Equal(t, testcase.called, called)
+			assert.Equal(t, testcase.status, recorder.Code)
+			assert.Equal(t, testcase.reason, core.RetrieveClaimsAbsenceReason(req))
+			assert.Nil(t, RetrieveJWTClaims(req))
+		})
+	}
+}
+
+type refreshingClient struct {
+	mockClient
+	rotated bool
+}
+
+func (c *refreshingClient) ValidateAndParseClaims(accessToken string) (*ic.JWTClaims, error) {
+	if !c.rotated {
+		return nil, errors.New("validateJWT: invalid key: getPublicKey: public key doesn't exist")
+	}
+	return c.mockClient.ValidateAndParseClaims(accessToken)
+}
+
+func (c *refreshingClient) RefreshKeys() error {
+	c.rotated = true
+	return nil
+}
+
+// nolint:paralleltest
+func TestAuth_RefreshKeysOnUnknownKeyID(t *testing.T) {
+	client := &refreshingClient{mockClient: mockClient{claims: &ic.JWTClaims{OrganizationID: "mock"}}}
+	var observed []core.VerificationFailure
+	filter := NewFilterWithOptions(client, &FilterInitializationOptions{
+		VerificationObserver: func(failure core.VerificationFailure) { observed = append(observed, failure) },
+	})
+
+	httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	httpReq.Header.Set("Authorization", "Bearer dummyToken")
+	called := false
+	chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+
+	filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), chain)
+
+	assert.True(t, called)
+	assert.True(t, client.rotated)
+	assert.Empty(t, observed)
+
+	httpReq.Header.Set("Authorization", "Bearer badToken")
+	recorder := httptest.NewRecorder()
+	filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(recorder), chain)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, []core.VerificationFailure{core.VerificationFailureInvalid}, observed)
+}
+
+// nolint:paralleltest
+func TestVerifier_ValidatesCookieReferer(t *testing.T) {
+	client := &mockClient{claims: &ic.JWTClaims{OrganizationID: "mock", ClientID: "client"}, redirectURI: "https://www.example.com"}
+	filter := core.NewFilter(NewFilterWithOptions(client, &FilterInitializationOptions{StrictRefererHeaderValidation: true}).Verifier())
+
+	for referer, allowed := range map[string]bool{
+		"https://www.example.com/page": true,
+		"https://www.example.net/page": false,
+		"":                             false,
+	} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		httpReq.AddCookie(&http.Cookie{Name: core.AccessTokenCookieKey, Value: "dummyToken"})
+		if referer != "" {
+			httpReq.Header.Set(constant.Referer, referer)
+		}
+
+		called := false
+		chain := &restful.FilterChain{Target: func(*restful.Request, *restful.Response) { called = true }}
+		filter.Auth()(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), chain)
+		assert.Equal(t, allowed, called, referer)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ic
+
+import (
+	"net/http"
+
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/auth/core"
+	"github.com/AccelByte/ic-go-sdk"
+	"github.com/emicklei/go-restful/v3"
+)
+
+// Claims wraps *ic.JWTClaims to implement core.Claims
+type Claims struct {
+	*ic.JWTClaims
+}
+
+// GetSubject returns the user ID of the token
+func (c Claims) GetSubject() string { return c.Subject }
+
+// GetClientID returns the client ID of the token
+func (c Claims) GetClientID() string { return c.ClientID }
+
+// GetScope returns the space separated scopes of the token
+func (c Claims) GetScope() string { return c.Scope }
+
+// GetAudience returns the audience of the token
+func (c Claims) GetAudience() []string { return c.Audience }
+
+// Attribute returns ClaimsAttribute so ic.RetrieveJWTClaims works behind a core.Filter
+func (c Claims) Attribute() string { return ClaimsAttribute }
+
+// Raw returns the wrapped *ic.JWTClaims
+func (c Claims) Raw() interface{} { return c.JWTClaims }
+
+type verifier struct {
+	filter              *Filter
+	allowEmptySubdomain bool
+}
+
+// NewVerifier creates a core.Verifier validating IC access tokens
+// Example:
+//
+//	core.NewFilter(iam.NewVerifier(iamClient), ic.NewVerifier(icClient))
+func NewVerifier(client ic.Client) core.Verifier {
+	return NewFilter(client).Verifier()
+}
+
+// Verifier returns a core.Verifier validating IC access tokens with the client of the filter.
+// The referer header of tokens from a cookie and the request subdomain are validated with the filter options,
+// like Auth does.
+func (filter *Filter) Verifier() core.Verifier {
+	return &verifier{filter: filter}
+}
+
+// Verify validates the token, refreshing the client keys once when the token key ID is unknown.
+func (v *verifier) Verify(token string) (core.Claims, error) {
+	claims, err := core.ValidateWithKeyRefresh(v.filter.icClient, v.filter.keyRefreshGuard, func() (*ic.JWTClaims, error) {
+		return v.filter.icClient.ValidateAndParseClaims(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return Claims{JWTClaims: claims}, nil
+}
+
+// ValidateReferer implements core.RefererValidator for the tokens from a cookie.
+func (v *verifier) ValidateReferer(req *restful.Request, claims core.Claims) bool {
+	jwtClaims, ok := claims.Raw().(*ic.JWTClaims)
+	if !ok {
+		return false
+	}
+
+	return v.filter.validateRefererHeader(req, jwtClaims, v.allowEmptySubdomain)
+}
+
+// ValidateRequest implements core.RequestValidator, it checks the request subdomain against the token organizations
+// when SubdomainValidationEnabled is set.
+func (v *verifier) ValidateRequest(req *restful.Request, claims core.Claims) error {
+	if !v.filter.options.SubdomainValidationEnabled || v.allowEmptySubdomain {
+		return nil
+	}
+	jwtClaims, ok := claims.Raw().(*ic.JWTClaims)
+	if !ok || !v.filter.validateSubdomainAgainstOrganization(core.GetHost(req.Request), jwtClaims) {
+		return respondError(http.StatusNotFound, SubdomainMismatch, "data not found: "+ErrorCodeMapping[SubdomainMismatch])
+	}
+
+	return nil
+}
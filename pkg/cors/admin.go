@@ -0,0 +1,83 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// ErrAdminWithoutFilter is returned by AdminWebService when no authorization filter is given.
+var ErrAdminWithoutFilter = errors.New("cors: admin web service requires an authorization filter")
+
+// AdminWebService returns a web service exposing the CORS cache administration routes under rootPath:
+//
+//	DELETE {rootPath}/cache                          evicts every cached config
+//	DELETE {rootPath}/cache/namespaces/{namespace}   evicts the config of a namespace
+//	GET    {rootPath}/config/namespaces/{namespace}  returns the effective config of a namespace with its provenance,
+//	                                                 as plain text with ?format=text
+//
+// The routes change the behavior of the service or expose its config, so filters must include an authorization
+// filter, e.g. an iam.Filter Auth with an admin permission. ErrAdminWithoutFilter is returned without filters.
+func (c *CrossOriginResourceSharing) AdminWebService(rootPath string, filters ...restful.FilterFunction) (*restful.WebService, error) {
+	if len(filters) == 0 || filters[0] == nil {
+		return nil, ErrAdminWithoutFilter
+	}
+
+	ws := new(restful.WebService)
+	ws.Path(rootPath).Produces(restful.MIME_JSON)
+	for _, filter := range filters {
+		ws.Filter(filter)
+	}
+
+	ws.Route(ws.DELETE("/cache").
+		To(c.handleInvalidateAll).
+		Doc("Evict every cached CORS config"))
+	ws.Route(ws.DELETE("/cache/namespaces/{namespace}").
+		To(c.handleInvalidateNamespace).
+		Doc("Evict the cached CORS config of a namespace").
+		Param(ws.PathParameter("namespace", "namespace").DataType("string")))
+	ws.Route(ws.GET("/config/namespaces/{namespace}").
+		To(c.handleInspectNamespace).
+		Doc("Get the effective CORS config of a namespace with the config each entry comes from").
+		Param(ws.PathParameter("namespace", "namespace").DataType("string")).
+		Param(ws.QueryParameter("format", "json (default) or text").DataType("string")).
+		Writes(ConfigInspection{}))
+
+	return ws, nil
+}
+
+func (c *CrossOriginResourceSharing) handleInvalidateAll(_ *restful.Request, resp *restful.Response) {
+	c.InvalidateAll()
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CrossOriginResourceSharing) handleInvalidateNamespace(req *restful.Request, resp *restful.Response) {
+	c.InvalidateNamespace(req.PathParameter("namespace"))
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CrossOriginResourceSharing) handleInspectNamespace(req *restful.Request, resp *restful.Response) {
+	inspection := c.InspectNamespace(req.Request.Context(), req.PathParameter("namespace"))
+	if req.QueryParameter("format") == "text" {
+		resp.Header().Set(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+		resp.WriteHeader(http.StatusOK)
+		_, _ = resp.Write([]byte(inspection.String()))
+		return
+	}
+	_ = resp.WriteHeaderAndJson(http.StatusOK, inspection, restful.MIME_JSON)
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"errors"
+)
+
+// ChainedConfigClient is a ConfigClient trying several clients in order, e.g. a local file overriding
+// the config service. The first config found is returned; a client without config for the namespace
+// or failing is skipped. When no client has a config, the errors of the failed clients are returned joined,
+// so the filter falls back to the static config the same way it does for a single failing client.
+type ChainedConfigClient struct {
+	clients []ConfigClient
+}
+
+// NewChainedConfigClient chains clients, nil clients are ignored.
+func NewChainedConfigClient(clients ...ConfigClient) *ChainedConfigClient {
+	c := &ChainedConfigClient{}
+	for _, client := range clients {
+		if client != nil {
+			c.clients = append(c.clients, client)
+		}
+	}
+	return c
+}
+
+// GetCORSConfig returns the config of the first client having one for namespace.
+func (c *ChainedConfigClient) GetCORSConfig(namespace string) (*CORSConfigValue, error) {
+	return c.GetCORSConfigWithContext(context.Background(), namespace)
+}
+
+// GetCORSConfigWithContext is GetCORSConfig, ctx bounds the clients implementing ContextConfigClient.
+func (c *ChainedConfigClient) GetCORSConfigWithContext(ctx context.Context, namespace string) (*CORSConfigValue, error) {
+	var errs []error
+	for _, client := range c.clients {
+		var config *CORSConfigValue
+		var err error
+		if contextClient, ok := client.(ContextConfigClient); ok {
+			config, err = contextClient.GetCORSConfigWithContext(ctx, namespace)
+		} else {
+			config, err = client.GetCORSConfig(namespace)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if config != nil {
+			return config, nil
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// GetSubdomainConfig returns the subdomain settings of the first client having some for publisherNamespace.
+func (c *ChainedConfigClient) GetSubdomainConfig(publisherNamespace string) (*CORSSubdomainConfig, error) {
+	return c.GetSubdomainConfigWithContext(context.Background(), publisherNamespace)
+}
+
+// GetSubdomainConfigWithContext is GetSubdomainConfig, ctx bounds the clients implementing ContextConfigClient.
+func (c *ChainedConfigClient) GetSubdomainConfigWithContext(ctx context.Context, publisherNamespace string) (*CORSSubdomainConfig, error) {
+	var errs []error
+	for _, client := range c.clients {
+		var config *CORSSubdomainConfig
+		var err error
+		if contextClient, ok := client.(ContextConfigClient); ok {
+			config, err = contextClient.GetSubdomainConfigWithContext(ctx, publisherNamespace)
+		} else {
+			config, err = client.GetSubdomainConfig(publisherNamespace)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if config != nil {
+			return config, nil
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// InvalidateCORSConfig invalidates namespace in the clients implementing ConfigInvalidator.
+func (c *ChainedConfigClient) InvalidateCORSConfig(namespace string) {
+	for _, client := range c.clients {
+		if invalidator, ok := client.(ConfigInvalidator); ok {
+			invalidator.InvalidateCORSConfig(namespace)
+		}
+	}
+}
+
+// InvalidateAllCORSConfigs invalidates the clients implementing ConfigInvalidator.
+func (c *ChainedConfigClient) InvalidateAllCORSConfigs() {
+	for _, client := range c.clients {
+		if invalidator, ok := client.(ConfigInvalidator); ok {
+			invalidator.InvalidateAllCORSConfigs()
+		}
+	}
+}
+
+// setInheritedCookiesAllowed implements inheritedCookiesAllowedSetter for the chained clients.
+func (c *ChainedConfigClient) setInheritedCookiesAllowed(cookiesAllowed bool) {
+	for _, client := range c.clients {
+		if setter, ok := client.(inheritedCookiesAllowedSetter); ok {
+			setter.setInheritedCookiesAllowed(cookiesAllowed)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChainedConfigClient(t *testing.T) {
+	override := NewMockConfigClient()
+	override.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://override.io"}}
+	override.errors["game3"] = errors.New("unavailable")
+
+	service := NewMockConfigClient()
+	service.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://service.io"}}
+	service.configs["game2"] = &CORSConfigValue{AllowedDomains: []string{"https://game2.io"}}
+	service.configs["game3"] = &CORSConfigValue{AllowedDomains: []string{"https://game3.io"}}
+	service.subdomainConfig = &CORSSubdomainConfig{SubdomainEnabled: true}
+
+	client := NewChainedConfigClient(override, nil, service)
+
+	tests := []struct {
+		namespace string
+		expected  string
+	}{
+		{"game1", "https://override.io"},
+		{"game2", "https://game2.io"},
+		{"game3", "https://game3.io"}, // the failing client is skipped
+	}
+	for _, tt := range tests {
+		config, err := client.GetCORSConfig(tt.namespace)
+		if err != nil || config == nil || !containsDomain(config.AllowedDomains, tt.expected) {
+			t.Errorf("GetCORSConfig(%q) = %+v, %v, expected %s", tt.namespace, config, err, tt.expected)
+		}
+	}
+
+	if config, err := client.GetCORSConfig("game4"); config != nil || err != nil {
+		t.Errorf("Expected (nil, nil) when no client has a config, got %+v, %v", config, err)
+	}
+
+	service.errors["game4"] = errors.New("timeout")
+	if _, err := client.GetCORSConfig("game4"); err == nil {
+		t.Error("Expected the errors to be returned when no client has a config")
+	}
+
+	if subdomain, err := client.GetSubdomainConfig("accelbyte"); err != nil || subdomain == nil || !subdomain.SubdomainEnabled {
+		t.Errorf("Expected the service subdomain config, got %+v, %v", subdomain, err)
+	}
+}
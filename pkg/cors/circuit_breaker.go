@@ -0,0 +1,262 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned by the config client while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("config service circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every call with ErrCircuitOpen until the cooldown elapsed
+	CircuitOpen
+	// CircuitHalfOpen lets a few trial calls through to decide whether to close or open again
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker of the config client. Zero fields fall back to
+// the defaults defined by defaultCircuitBreakerConfig.
+type CircuitBreakerConfig struct {
+	Disabled bool
+
+	WindowSize       int           // number of most recent calls the failure ratio is computed on
+	MinRequests      int           // minimum calls in the window before the circuit can open
+	FailureRatio     float64       // failure ratio in the window opening the circuit
+	Cooldown         time.Duration // how long the circuit stays open before trial calls are let through
+	HalfOpenMaxCalls int           // trial calls in half-open state; all must succeed to close the circuit
+
+	// OnStateChange is called on every state change, e.g. to export the state as a metric.
+	// It must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+var defaultCircuitBreakerConfig = CircuitBreakerConfig{
+	WindowSize:       20,
+	MinRequests:      10,
+	FailureRatio:     0.5,
+	Cooldown:         10 * time.Second,
+	HalfOpenMaxCalls: 1,
+}
+
+// CircuitBreaker stops calls to a failing service for a cooldown period.
+// The circuit opens when the failure ratio of the last WindowSize calls reaches FailureRatio.
+// After Cooldown, HalfOpenMaxCalls trial calls are let through: the circuit closes when they all succeed
+// and opens again on the first failure.
+// A nil *CircuitBreaker lets every call through.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	openedAt time.Time
+	window   []bool // ring buffer of call outcomes, true is a failure
+	next     int
+	count    int
+	failures int
+
+	halfOpenCalls     int // trial calls let through in half-open state
+	halfOpenSuccesses int
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker, or returns nil when config.Disabled is set.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.Disabled {
+		return nil
+	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaultCircuitBreakerConfig.WindowSize
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaultCircuitBreakerConfig.MinRequests
+	}
+	if config.MinRequests > config.WindowSize {
+		config.MinRequests = config.WindowSize
+	}
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = defaultCircuitBreakerConfig.FailureRatio
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultCircuitBreakerConfig.Cooldown
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = defaultCircuitBreakerConfig.HalfOpenMaxCalls
+	}
+
+	return &CircuitBreaker{
+		config: config,
+		now:    time.Now,
+		window: make([]bool, config.WindowSize),
+	}
+}
+
+// State returns the current state. An open circuit whose cooldown elapsed is reported half-open.
+func (cb *CircuitBreaker) State() CircuitState {
+	if cb == nil {
+		return CircuitClosed
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.checkCooldown()
+
+	return cb.state
+}
+
+// Allow returns ErrCircuitOpen when the call must not be made. Otherwise the caller must report
+// the outcome of the call with Success, Failure or Cancel.
+func (cb *CircuitBreaker) Allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.checkCooldown()
+
+	switch cb.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.halfOpenCalls >= cb.config.HalfOpenMaxCalls {
+			return ErrCircuitOpen
+		}
+		cb.halfOpenCalls++
+	}
+
+	return nil
+}
+
+// Success records a successful call.
+func (cb *CircuitBreaker) Success() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.record(false)
+	case CircuitHalfOpen:
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.config.HalfOpenMaxCalls {
+			cb.setState(CircuitClosed)
+		}
+	}
+}
+
+// Failure records a failed call.
+func (cb *CircuitBreaker) Failure() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitClosed:
+		cb.record(true)
+		if cb.count >= cb.config.MinRequests && float64(cb.failures) >= cb.config.FailureRatio*float64(cb.count) {
+			cb.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		cb.setState(CircuitOpen)
+	}
+}
+
+// Cancel records a call abandoned by its caller, which tells nothing about the service health.
+func (cb *CircuitBreaker) Cancel() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitHalfOpen && cb.halfOpenCalls > 0 {
+		cb.halfOpenCalls--
+	}
+}
+
+// record adds a call outcome to the window, replacing the oldest one when the window is full.
+func (cb *CircuitBreaker) record(failure bool) {
+	if cb.count == len(cb.window) {
+		if cb.window[cb.next] {
+			cb.failures--
+		}
+	} else {
+		cb.count++
+	}
+	cb.window[cb.next] = failure
+	if failure {
+		cb.failures++
+	}
+	cb.next = (cb.next + 1) % len(cb.window)
+}
+
+func (cb *CircuitBreaker) checkCooldown() {
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.config.Cooldown {
+		cb.setState(CircuitHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	from := cb.state
+	cb.state = state
+
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = cb.now()
+		logrus.Warnf("cors: config service circuit breaker opened, using static CORS config for %s", cb.config.Cooldown)
+	case CircuitHalfOpen:
+		cb.halfOpenCalls = 0
+		cb.halfOpenSuccesses = 0
+		logrus.Infof("cors: config service circuit breaker half-open, trying %d call(s)", cb.config.HalfOpenMaxCalls)
+	case CircuitClosed:
+		cb.next, cb.count, cb.failures = 0, 0, 0
+		logrus.Infof("cors: config service circuit breaker closed")
+	}
+
+	if cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(from, state)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestCircuitBreaker(config CircuitBreakerConfig) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(config)
+	cb.now = clock.Now
+	return cb, clock
+}
+
+func TestCircuitBreakerOpensOnFailureRatio(t *testing.T) {
+	cb, _ := newTestCircuitBreaker(CircuitBreakerConfig{WindowSize: 10, MinRequests: 4, FailureRatio: 0.5})
+
+	// below MinRequests the circuit stays closed whatever the ratio
+	cb.Allow()
+	cb.Success()
+	for i := 0; i < 2; i++ {
+		cb.Allow()
+		cb.Failure()
+	}
+	if cb.State() != CircuitClosed {
+		t.Fatalf("Expected closed circuit below MinRequests, got %s", cb.State())
+	}
+
+	// 3 failures out of 4 calls
+	cb.Allow()
+	cb.Failure()
+	if cb.State() != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", cb.State())
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestCircuitBreakerStaysClosedBelowRatio(t *testing.T) {
+	cb, _ := newTestCircuitBreaker(CircuitBreakerConfig{WindowSize: 4, MinRequests: 4, FailureRatio: 0.5})
+
+	// the window only keeps the last 4 outcomes, so old failures are forgotten
+	outcomes := []bool{true, true, false, false, false, true, false}
+	for _, failure := range outcomes {
+		cb.Allow()
+		if failure {
+			cb.Failure()
+		} else {
+			cb.Success()
+		}
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	var transitions []string
+	cb, clock := newTestCircuitBreaker(CircuitBreakerConfig{
+		WindowSize:       2,
+		MinRequests:      2,
+		Cooldown:         10 * time.Second,
+		HalfOpenMaxCalls: 1,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	for i := 0; i < 2; i++ {
+		cb.Allow()
+		cb.Failure()
+	}
+
+	clock.Advance(5 * time.Second)
+	if err := cb.Allow(); err == nil {
+		t.Fatal("Expected calls to be rejected during the cooldown")
+	}
+
+	// trial call fails: open again for another cooldown
+	clock.Advance(5 * time.Second)
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Expected a trial call after the cooldown, got %v", err)
+	}
+	if err := cb.Allow(); err == nil {
+		t.Error("Expected a single trial call in half-open state")
+	}
+	cb.Failure()
+	if cb.State() != CircuitOpen {
+		t.Fatalf("Expected open circuit after a failed trial call, got %s", cb.State())
+	}
+
+	// cancelled trial call frees its slot
+	clock.Advance(10 * time.Second)
+	cb.Allow()
+	cb.Cancel()
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Expected a new trial call after a cancelled one, got %v", err)
+	}
+	cb.Success()
+	if cb.State() != CircuitClosed {
+		t.Fatalf("Expected closed circuit after a successful trial call, got %s", cb.State())
+	}
+
+	expected := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Expected transitions %v, got %v", expected, transitions)
+			break
+		}
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{Disabled: true})
+	for i := 0; i < 100; i++ {
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("Expected disabled circuit breaker to allow every call, got %v", err)
+		}
+		cb.Failure()
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("Expected disabled circuit breaker to report closed, got %s", cb.State())
+	}
+}
+
+func TestConfigClientCircuitBreaker(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, time.Minute, nil, TransportConfig{
+		MaxRetries:     -1,
+		CircuitBreaker: CircuitBreakerConfig{WindowSize: 3, MinRequests: 3, Cooldown: time.Hour},
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetCORSConfig("test-ns"); err == nil {
+			t.Fatal("Expected error for server error")
+		}
+	}
+	if client.CircuitState() != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", client.CircuitState())
+	}
+
+	_, err := client.GetCORSConfig("test-ns")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if _, err := client.GetSubdomainConfig("publisher"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen for subdomain config, got %v", err)
+	}
+	if requestCount != 3 {
+		t.Errorf("Expected no request while the circuit is open, got %d requests", requestCount)
+	}
+}
+
+func TestConfigClientNotFoundDoesNotOpenCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, time.Minute, nil, TransportConfig{
+		CircuitBreaker: CircuitBreakerConfig{WindowSize: 2, MinRequests: 2},
+	})
+	for i := 0; i < 5; i++ {
+		client.GetSubdomainConfig("publisher")
+	}
+	if client.CircuitState() != CircuitClosed {
+		t.Errorf("Expected 404 responses to keep the circuit closed, got %s", client.CircuitState())
+	}
+}
@@ -15,45 +15,274 @@
 package cors
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/bluele/gcache"
+	"github.com/sirupsen/logrus"
 )
 
 const defaultCacheSize = 200
 
+// ConfigCacheOptions configures a ConfigCache. Zero fields fall back to defaults.
+type ConfigCacheOptions struct {
+	Size int           // maximum number of namespaces kept, with LRU eviction (default defaultCacheSize)
+	TTL  time.Duration // how long a loaded config is fresh
+
+	// StaleTTL is how long a config stays usable after TTL. A stale config is returned immediately
+	// while it is reloaded in the background (stale-while-revalidate). 0 disables it.
+	StaleTTL time.Duration
+
+	// NotFoundTTL is how long a namespace without config is cached, usually shorter than TTL
+	// so a newly created config is picked up quickly (default TTL).
+	NotFoundTTL time.Duration
+
+	// ErrorTTL is how long a loader failure is cached, so a failing config service isn't called
+	// on every request. When a stale config is available it is served instead of the failure.
+	// 0 disables negative caching of failures. Context cancellations and deadlines are never cached.
+	ErrorTTL time.Duration
+
+	// Validator checks loaded configs before they are cached (default NewConfigValidatorFromEnv).
+	// A config rejected by the validator is handled as a loader failure.
+	Validator *ConfigValidator
+
+	// LoadTimeout bounds each loader call, or the deadline of the Get call that started it when later.
+	// The loads are detached from the cancellation of that call, since concurrent misses share them.
+	// 0 leaves the loads unbounded.
+	LoadTimeout time.Duration
+
+	// Metrics counts the cache hits, misses and evictions (optional)
+	Metrics Metrics
+}
+
 // ConfigCache is a loading cache for CORS configurations backed by gcache.
 // When a namespace is not present, it automatically calls the configured loader function
 // to fetch the config, then caches the result for the configured TTL duration.
+// Concurrent misses for the same namespace share a single loader call.
 type ConfigCache struct {
-	gc gcache.Cache
+	gc      gcache.Cache
+	loader  func(context.Context, string) (*CORSConfigValue, error)
+	options ConfigCacheOptions
+	now     func() time.Time
+
+	mu           sync.Mutex
+	calls        map[string]*configLoadCall
+	invalidating bool // Invalidate is removing an entry, which is not an eviction
+}
+
+// configCacheEntry is a cached loader result. Entries are never modified once stored.
+type configCacheEntry struct {
+	value      *CORSConfigValue
+	err        error
+	expiresAt  time.Time
+	staleUntil time.Time
+}
+
+// configLoadCall is an in-flight loader call shared by concurrent misses.
+type configLoadCall struct {
+	done  chan struct{}
+	value *CORSConfigValue
+	err   error
 }
 
 // NewConfigCache creates a new LRU loading cache backed by gcache.
 // The loader is called automatically on cache miss; its result is cached for the TTL duration.
 // Use defaultCacheSize (200) entries maximum with LRU eviction policy.
 func NewConfigCache(ttl time.Duration, loader func(string) (*CORSConfigValue, error)) *ConfigCache {
-	gc := gcache.New(defaultCacheSize).
-		LRU().
-		Expiration(ttl).
-		LoaderFunc(func(key interface{}) (interface{}, error) {
-			return loader(key.(string))
-		}).
-		Build()
-	return &ConfigCache{gc: gc}
+	return NewConfigCacheWithContext(ttl, func(_ context.Context, namespace string) (*CORSConfigValue, error) {
+		return loader(namespace)
+	})
+}
+
+// NewConfigCacheWithContext creates a new LRU loading cache whose loader receives the context of the Get call
+// that missed, so a fetch can be bounded by the caller deadline.
+func NewConfigCacheWithContext(ttl time.Duration, loader func(context.Context, string) (*CORSConfigValue, error)) *ConfigCache {
+	return NewConfigCacheWithOptions(ConfigCacheOptions{TTL: ttl}, loader)
+}
+
+// NewConfigCacheWithOptions creates a new LRU loading cache with stale-while-revalidate and negative caching
+// configured by options.
+func NewConfigCacheWithOptions(options ConfigCacheOptions, loader func(context.Context, string) (*CORSConfigValue, error)) *ConfigCache {
+	if options.Size <= 0 {
+		options.Size = defaultCacheSize
+	}
+	if options.NotFoundTTL <= 0 {
+		options.NotFoundTTL = options.TTL
+	}
+	if options.Validator == nil {
+		options.Validator = NewConfigValidatorFromEnv()
+	}
+	options.Metrics = metricsOrNoop(options.Metrics)
+	cc := &ConfigCache{
+		loader:  loader,
+		options: options,
+		now:     time.Now,
+		calls:   make(map[string]*configLoadCall),
+	}
+	// the entries are set and removed with mu held, so invalidating is read safely
+	cc.gc = gcache.New(options.Size).LRU().EvictedFunc(func(_, _ interface{}) {
+		if !cc.invalidating {
+			cc.options.Metrics.ConfigCacheEviction()
+		}
+	}).Build()
+	return cc
 }
 
 // Get retrieves the CORS config for the given namespace.
 // On a cache miss the loader is invoked automatically.
 // Returns (nil, nil) when the namespace has no config (e.g. 404 from the config service).
 func (cc *ConfigCache) Get(namespace string) (*CORSConfigValue, error) {
+	return cc.GetWithContext(context.Background(), namespace)
+}
+
+// GetWithContext is Get returning early with ctx.Err() when ctx is done before the config is loaded.
+// The loader receives the values of ctx, not its cancellation: the load is shared with the concurrent misses
+// and bounded by LoadTimeout, extended to the deadline of ctx.
+// A stale config is returned without waiting while it is reloaded in the background.
+func (cc *ConfigCache) GetWithContext(ctx context.Context, namespace string) (*CORSConfigValue, error) {
+	now := cc.now()
+	if entry := cc.entry(namespace); entry != nil {
+		if now.Before(entry.expiresAt) {
+			cc.options.Metrics.ConfigCacheHit()
+			return entry.value, entry.err
+		}
+		if entry.err == nil && now.Before(entry.staleUntil) {
+			cc.options.Metrics.ConfigCacheHit()
+			cc.revalidate(namespace, entry)
+			return entry.value, nil
+		}
+	}
+	cc.options.Metrics.ConfigCacheMiss()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	call, leader := cc.startLoad(namespace)
+	if leader {
+		go cc.load(ctx, namespace, call, nil)
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (cc *ConfigCache) entry(namespace string) *configCacheEntry {
 	v, err := cc.gc.Get(namespace)
 	if err != nil {
-		return nil, err
+		return nil
+	}
+	return v.(*configCacheEntry)
+}
+
+// startLoad returns the in-flight load of namespace, or registers a new one when leader is true.
+func (cc *ConfigCache) startLoad(namespace string) (call *configLoadCall, leader bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if call, ok := cc.calls[namespace]; ok {
+		return call, false
 	}
-	if v == nil {
-		return nil, nil
+	call = &configLoadCall{done: make(chan struct{})}
+	cc.calls[namespace] = call
+	return call, true
+}
+
+// revalidate reloads a stale entry in the background unless a load is already in flight.
+func (cc *ConfigCache) revalidate(namespace string, stale *configCacheEntry) {
+	call, leader := cc.startLoad(namespace)
+	if !leader {
+		return
 	}
-	return v.(*CORSConfigValue), nil
+	go cc.load(context.Background(), namespace, call, stale)
 }
 
+// load calls the loader with the values of ctx, stores its result and releases the callers waiting for call.
+// The result is not stored when the namespace was invalidated during the load.
+func (cc *ConfigCache) load(ctx context.Context, namespace string, call *configLoadCall, stale *configCacheEntry) {
+	timeout := cc.options.LoadTimeout
+	if deadline, ok := ctx.Deadline(); ok && timeout > 0 && time.Until(deadline) > timeout {
+		timeout = time.Until(deadline)
+	}
+	ctx = context.WithoutCancel(ctx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	call.value, call.err = cc.loader(ctx, namespace)
+	if call.err == nil {
+		call.value, call.err = cc.options.Validator.Validate(namespace, call.value)
+	}
+
+	cc.mu.Lock()
+	if cc.calls[namespace] == call {
+		cc.store(namespace, call.value, call.err, stale)
+		delete(cc.calls, namespace)
+	}
+	cc.mu.Unlock()
+	close(call.done)
+}
+
+// Invalidate removes the cached config of namespace, so the next Get loads it again.
+// A load in flight for namespace is not cached.
+func (cc *ConfigCache) Invalidate(namespace string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	delete(cc.calls, namespace)
+	cc.invalidating = true
+	cc.gc.Remove(namespace)
+	cc.invalidating = false
+}
+
+// InvalidateInherited removes the cached configs inherited from namespace, see CORSConfigValue.SourceNamespace,
+// and the cached namespaces without config, which may inherit a config created in namespace.
+func (cc *ConfigCache) InvalidateInherited(namespace string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.invalidating = true
+	for key, v := range cc.gc.GetALL(false) {
+		entry := v.(*configCacheEntry)
+		if entry.err == nil && (entry.value == nil || entry.value.SourceNamespace == namespace) {
+			cc.gc.Remove(key)
+		}
+	}
+	cc.invalidating = false
+}
+
+// InvalidateAll removes every cached config.
+func (cc *ConfigCache) InvalidateAll() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.calls = make(map[string]*configLoadCall)
+	cc.gc.Purge()
+}
+
+func (cc *ConfigCache) store(namespace string, value *CORSConfigValue, err error, stale *configCacheEntry) {
+	now := cc.now()
+	switch {
+	case err == nil:
+		ttl := cc.options.TTL
+		if value == nil {
+			ttl = cc.options.NotFoundTTL
+		}
+		expiresAt := now.Add(ttl)
+		_ = cc.gc.Set(namespace, &configCacheEntry{value: value, expiresAt: expiresAt, staleUntil: expiresAt.Add(cc.options.StaleTTL)})
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// the next Get tries again, a slow config service is not a missing config
+		logrus.Warnf("cors: timed out loading CORS config for namespace %q: %v", namespace, err)
+	case stale != nil:
+		// keep serving the stale config; wait ErrorTTL before the next background reload
+		logrus.Warnf("cors: failed to revalidate CORS config for namespace %q, serving stale config: %v", namespace, err)
+		if cc.options.ErrorTTL > 0 {
+			_ = cc.gc.Set(namespace, &configCacheEntry{value: stale.value, expiresAt: now.Add(cc.options.ErrorTTL), staleUntil: stale.staleUntil})
+		}
+	case cc.options.ErrorTTL > 0:
+		_ = cc.gc.Set(namespace, &configCacheEntry{err: err, expiresAt: now.Add(cc.options.ErrorTTL)})
+	}
+}
@@ -15,8 +15,11 @@
 package cors
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -143,3 +146,312 @@ func TestCacheLoaderErrorNotCached(t *testing.T) {
 		t.Error("Expected config on third call")
 	}
 }
+
+func TestCacheConcurrentMissesShareLoad(t *testing.T) {
+	var loadCount int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		atomic.AddInt32(&loadCount, 1)
+		<-release
+		return &CORSConfigValue{AllowedDomains: []string{"https://example.com"}}, nil
+	}
+	cache := NewConfigCacheWithContext(1*time.Minute, loader)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := cache.Get("test-ns"); err != nil || result == nil {
+				t.Errorf("Unexpected result %v, error %v", result, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loadCount != 1 {
+		t.Errorf("Expected concurrent misses to share one load, got %d", loadCount)
+	}
+}
+
+func TestCacheGetWithContextPassesContext(t *testing.T) {
+	type ctxKey struct{}
+	var received interface{}
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		received = ctx.Value(ctxKey{})
+		return nil, ctx.Err()
+	}
+	cache := NewConfigCacheWithContext(1*time.Minute, loader)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	if _, err := cache.GetWithContext(ctx, "test-ns"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if received != "value" {
+		t.Errorf("Expected loader to receive the caller context, got %v", received)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.GetWithContext(cancelled, "other-ns"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCacheSharedLoadIgnoresCallerCancellation(t *testing.T) {
+	release := make(chan struct{})
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &CORSConfigValue{AllowedDomains: []string{"https://example.com"}}, nil
+	}
+	cache := NewConfigCacheWithOptions(ConfigCacheOptions{TTL: time.Minute, ErrorTTL: time.Minute, LoadTimeout: time.Minute}, loader)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetWithContext(leaderCtx, "test-ns")
+		leaderErr <- err
+	}()
+	for {
+		cache.mu.Lock()
+		pending := len(cache.calls)
+		cache.mu.Unlock()
+		if pending == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	type result struct {
+		config *CORSConfigValue
+		err    error
+	}
+	waiter := make(chan result, 1)
+	go func() {
+		config, err := cache.Get("test-ns")
+		waiter <- result{config, err}
+	}()
+
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to return context.Canceled, got %v", err)
+	}
+	close(release)
+	if r := <-waiter; r.err != nil || r.config == nil {
+		t.Errorf("Expected the waiting caller to get the config, got %v, error %v", r.config, r.err)
+	}
+}
+
+func TestCacheDoesNotCacheContextErrors(t *testing.T) {
+	var loadCount int32
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		if atomic.AddInt32(&loadCount, 1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &CORSConfigValue{}, nil
+	}
+	cache := NewConfigCacheWithOptions(ConfigCacheOptions{TTL: time.Minute, ErrorTTL: time.Minute, LoadTimeout: 10 * time.Millisecond}, loader)
+
+	if _, err := cache.Get("test-ns"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the load to be bounded by LoadTimeout, got %v", err)
+	}
+	if config, err := cache.Get("test-ns"); err != nil || config == nil {
+		t.Errorf("Expected the deadline not to be cached, got %v, error %v", config, err)
+	}
+	if loadCount != 2 {
+		t.Errorf("Expected 2 loads, got %d", loadCount)
+	}
+}
+
+// fakeClock is a manually advanced clock for the cache expiry tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(options ConfigCacheOptions, loader func(context.Context, string) (*CORSConfigValue, error)) (*ConfigCache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cache := NewConfigCacheWithOptions(options, loader)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+// waitForLoads waits until the background reload has stored its result.
+func waitForLoads(cache *ConfigCache) {
+	for {
+		cache.mu.Lock()
+		pending := len(cache.calls)
+		cache.mu.Unlock()
+		if pending == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheServesStaleWhileRevalidating(t *testing.T) {
+	var loadCount int32
+	release := make(chan struct{}, 1)
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		if atomic.AddInt32(&loadCount, 1) > 1 {
+			<-release
+		}
+		return &CORSConfigValue{AllowedDomains: []string{fmt.Sprintf("https://v%d.example.com", atomic.LoadInt32(&loadCount))}}, nil
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Minute, StaleTTL: time.Minute}, loader)
+
+	first, _ := cache.Get("test-ns")
+	clock.Advance(90 * time.Second)
+
+	// stale: returned without waiting for the reload
+	stale, err := cache.Get("test-ns")
+	if err != nil || stale != first {
+		t.Fatalf("Expected stale config to be served, got %v, error %v", stale, err)
+	}
+	if again, _ := cache.Get("test-ns"); again != first {
+		t.Error("Expected stale config to be served while the reload is in flight")
+	}
+
+	release <- struct{}{}
+	waitForLoads(cache)
+	if loadCount != 2 {
+		t.Errorf("Expected one background reload, got %d loads", loadCount-1)
+	}
+	refreshed, _ := cache.Get("test-ns")
+	if refreshed == first || refreshed.AllowedDomains[0] != "https://v2.example.com" {
+		t.Errorf("Expected refreshed config after revalidation, got %v", refreshed)
+	}
+}
+
+func TestCacheReloadsSynchronouslyAfterStaleTTL(t *testing.T) {
+	var loadCount int32
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		atomic.AddInt32(&loadCount, 1)
+		return &CORSConfigValue{}, nil
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Minute, StaleTTL: time.Minute}, loader)
+
+	first, _ := cache.Get("test-ns")
+	clock.Advance(3 * time.Minute)
+	if result, _ := cache.Get("test-ns"); result == first {
+		t.Error("Expected a config older than TTL+StaleTTL to be reloaded before returning")
+	}
+	if loadCount != 2 {
+		t.Errorf("Expected 2 loads, got %d", loadCount)
+	}
+}
+
+func TestCacheKeepsStaleConfigWhenRevalidationFails(t *testing.T) {
+	var loadCount int32
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		if atomic.AddInt32(&loadCount, 1) > 1 {
+			return nil, fmt.Errorf("service unavailable")
+		}
+		return &CORSConfigValue{}, nil
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Minute, StaleTTL: 5 * time.Minute, ErrorTTL: 10 * time.Second}, loader)
+
+	first, _ := cache.Get("test-ns")
+	clock.Advance(2 * time.Minute)
+	cache.Get("test-ns")
+	waitForLoads(cache)
+
+	// the failure is not served, and no reload is attempted again within ErrorTTL
+	for i := 0; i < 3; i++ {
+		if result, err := cache.Get("test-ns"); err != nil || result != first {
+			t.Fatalf("Expected stale config after a failed revalidation, got %v, error %v", result, err)
+		}
+	}
+	if loadCount != 2 {
+		t.Errorf("Expected no reload within ErrorTTL, got %d loads", loadCount)
+	}
+
+	clock.Advance(11 * time.Second)
+	cache.Get("test-ns")
+	waitForLoads(cache)
+	if loadCount != 3 {
+		t.Errorf("Expected a reload after ErrorTTL, got %d loads", loadCount)
+	}
+}
+
+func TestCacheNotFoundTTL(t *testing.T) {
+	var loadCount int32
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		atomic.AddInt32(&loadCount, 1)
+		return nil, nil
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Hour, NotFoundTTL: 10 * time.Second}, loader)
+
+	cache.Get("test-ns")
+	clock.Advance(5 * time.Second)
+	cache.Get("test-ns")
+	if loadCount != 1 {
+		t.Errorf("Expected missing config to be cached within NotFoundTTL, got %d loads", loadCount)
+	}
+
+	clock.Advance(6 * time.Second)
+	cache.Get("test-ns")
+	if loadCount != 2 {
+		t.Errorf("Expected missing config to be reloaded after NotFoundTTL, got %d loads", loadCount)
+	}
+}
+
+func TestCacheErrorTTL(t *testing.T) {
+	var loadCount int32
+	loadErr := fmt.Errorf("service unavailable")
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		atomic.AddInt32(&loadCount, 1)
+		return nil, loadErr
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Hour, ErrorTTL: 5 * time.Second}, loader)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get("test-ns"); err != loadErr {
+			t.Fatalf("Expected cached loader error, got %v", err)
+		}
+	}
+	if loadCount != 1 {
+		t.Errorf("Expected failure to be cached within ErrorTTL, got %d loads", loadCount)
+	}
+
+	clock.Advance(6 * time.Second)
+	cache.Get("test-ns")
+	if loadCount != 2 {
+		t.Errorf("Expected reload after ErrorTTL, got %d loads", loadCount)
+	}
+}
+
+func TestCacheSize(t *testing.T) {
+	var loadCount int32
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		atomic.AddInt32(&loadCount, 1)
+		return &CORSConfigValue{}, nil
+	}
+	cache, _ := newTestCache(ConfigCacheOptions{TTL: time.Hour, Size: 2}, loader)
+
+	cache.Get("ns1")
+	cache.Get("ns2")
+	cache.Get("ns3") // evicts ns1
+	cache.Get("ns1")
+	if loadCount != 4 {
+		t.Errorf("Expected least recently used namespace to be evicted, got %d loads", loadCount)
+	}
+}
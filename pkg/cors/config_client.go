@@ -17,10 +17,13 @@ package cors
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	iam "github.com/AccelByte/iam-go-sdk/v2"
@@ -34,6 +37,19 @@ type TransportConfig struct {
 	HTTPMaxIdleConns        int
 	HTTPMaxIdleConnsPerHost int
 	HTTPIdleConnTimeout     time.Duration
+
+	// Retries of config service calls failing with a network error or a 5xx status.
+	// A negative MaxRetries disables retries.
+	MaxRetries     int
+	RetryBaseDelay time.Duration // backoff before the first retry, doubled on each retry, with full jitter
+	RetryMaxDelay  time.Duration // upper bound of the backoff
+
+	// CircuitBreaker stops calling the config service while it is failing, so the filter
+	// falls back to the static config without waiting for the timeout.
+	CircuitBreaker CircuitBreakerConfig
+
+	// Metrics records the duration and status of the config service calls (optional)
+	Metrics Metrics
 }
 
 var defaultTransportConfig = TransportConfig{
@@ -41,6 +57,42 @@ var defaultTransportConfig = TransportConfig{
 	HTTPMaxIdleConns:        100,
 	HTTPMaxIdleConnsPerHost: 10,
 	HTTPIdleConnTimeout:     60 * time.Second,
+	MaxRetries:              2,
+	RetryBaseDelay:          20 * time.Millisecond,
+	RetryMaxDelay:           200 * time.Millisecond,
+}
+
+// retryPolicy is the retry part of TransportConfig with defaults applied.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func newRetryPolicy(cfg TransportConfig) retryPolicy {
+	policy := retryPolicy{maxRetries: cfg.MaxRetries, baseDelay: cfg.RetryBaseDelay, maxDelay: cfg.RetryMaxDelay}
+	if policy.maxRetries == 0 {
+		policy.maxRetries = defaultTransportConfig.MaxRetries
+	}
+	if policy.maxRetries < 0 {
+		policy.maxRetries = 0
+	}
+	if policy.baseDelay == 0 {
+		policy.baseDelay = defaultTransportConfig.RetryBaseDelay
+	}
+	if policy.maxDelay == 0 {
+		policy.maxDelay = defaultTransportConfig.RetryMaxDelay
+	}
+	return policy
+}
+
+// backoff returns a random delay in [0, min(maxDelay, baseDelay*2^retry)).
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.baseDelay << retry
+	if delay <= 0 || delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
 }
 
 func newHTTPClient(cfg TransportConfig) *http.Client {
@@ -83,6 +135,22 @@ type ConfigClient interface {
 	GetSubdomainConfig(publisherNamespace string) (*CORSSubdomainConfig, error)
 }
 
+// ContextConfigClient is a ConfigClient whose calls can be bounded by a context.
+// The filter uses it when available to enforce ConfigFetchTimeout.
+type ContextConfigClient interface {
+	ConfigClient
+	GetCORSConfigWithContext(ctx context.Context, namespace string) (*CORSConfigValue, error)
+	GetSubdomainConfigWithContext(ctx context.Context, publisherNamespace string) (*CORSSubdomainConfig, error)
+}
+
+// ConfigInvalidator is implemented by config clients caching the CORS configs they fetch.
+// CrossOriginResourceSharing uses it to evict entries when a config change is pushed.
+// InvalidateCORSConfig must also evict the configs inherited from namespace, e.g. the game configs of a studio.
+type ConfigInvalidator interface {
+	InvalidateCORSConfig(namespace string)
+	InvalidateAllCORSConfigs()
+}
+
 // DefaultConfigClient is the HTTP transport implementation of ConfigClient.
 // It uses a gcache loading cache: on a cache miss the cache automatically calls
 // fetchFromService, so GetCORSConfig never needs to manage cache reads/writes manually.
@@ -91,47 +159,69 @@ type DefaultConfigClient struct {
 	cache      *ConfigCache
 	httpClient *http.Client
 	iamClient  iam.Client // Optional IAM client for obtaining bearer tokens (can be nil)
+	retry      retryPolicy
+	breaker    *CircuitBreaker
+	metrics    Metrics
 }
 
 // NewConfigClientWithIAM creates a config client with IAM bearer-token authentication.
 // The iamClient is called before each config service request to obtain a fresh token.
-// HTTP transport is configured via cfg; zero fields fall back to defaults.
+// HTTP transport and retries are configured via cfg; zero fields fall back to defaults.
 // HTTP requests are traced via OpenTelemetry using otelhttp.
 func NewConfigClientWithIAM(baseURL string, ttl time.Duration, iamClient iam.Client, cfg TransportConfig) *DefaultConfigClient {
+	return NewConfigClientWithCacheOptions(baseURL, ConfigCacheOptions{TTL: ttl}, iamClient, cfg)
+}
+
+// NewConfigClientWithCacheOptions is NewConfigClientWithIAM with the cache configured by cacheOptions,
+// e.g. to enable stale-while-revalidate and negative caching or to change the cache size.
+func NewConfigClientWithCacheOptions(baseURL string, cacheOptions ConfigCacheOptions, iamClient iam.Client, cfg TransportConfig) *DefaultConfigClient {
 	c := &DefaultConfigClient{
 		baseURL:    baseURL,
 		iamClient:  iamClient,
 		httpClient: newHTTPClient(cfg),
+		retry:      newRetryPolicy(cfg),
+		breaker:    NewCircuitBreaker(cfg.CircuitBreaker),
+		metrics:    metricsOrNoop(cfg.Metrics),
 	}
-	c.cache = NewConfigCache(ttl, c.fetchFromService)
+	c.cache = NewConfigCacheWithOptions(cacheOptions, c.fetchFromService)
 	return c
 }
 
 // GetCORSConfig returns the CORS configuration for the given namespace.
-// The underlying gcache loading cache calls fetchFromService automatically on a miss.
+// The underlying loading cache calls fetchFromService automatically on a miss.
 // Returns (nil, nil) when the namespace has no config (graceful 404 fallback).
 func (c *DefaultConfigClient) GetCORSConfig(namespace string) (*CORSConfigValue, error) {
-	return c.cache.Get(namespace)
+	return c.GetCORSConfigWithContext(context.Background(), namespace)
+}
+
+// GetCORSConfigWithContext is GetCORSConfig with the config service call bounded by ctx.
+func (c *DefaultConfigClient) GetCORSConfigWithContext(ctx context.Context, namespace string) (*CORSConfigValue, error) {
+	return c.cache.GetWithContext(ctx, namespace)
+}
+
+// InvalidateCORSConfig evicts the cached CORS config of namespace and the cached configs depending on it:
+// the configs inherited from namespace and the namespaces without config.
+func (c *DefaultConfigClient) InvalidateCORSConfig(namespace string) {
+	c.cache.Invalidate(namespace)
+	c.cache.InvalidateInherited(namespace)
+}
+
+// InvalidateAllCORSConfigs evicts every cached CORS config.
+func (c *DefaultConfigClient) InvalidateAllCORSConfigs() {
+	c.cache.InvalidateAll()
 }
 
 // GetSubdomainConfig fetches subdomain extraction settings for the publisher namespace
 // from the CORS_SUBDOMAIN config key. Returns (nil, nil) when no config exists (404).
 func (c *DefaultConfigClient) GetSubdomainConfig(publisherNamespace string) (*CORSSubdomainConfig, error) {
-	url := fmt.Sprintf("%s/v1/admin/namespaces/%s/configs/CORS_SUBDOMAIN", c.baseURL, publisherNamespace)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, newConfigFetchError(publisherNamespace, err)
-	}
+	return c.GetSubdomainConfigWithContext(context.Background(), publisherNamespace)
+}
 
-	if c.iamClient != nil {
-		token := c.iamClient.ClientToken()
-		if token != "" {
-			req.Header.Set("Authorization", "Bearer "+token)
-		}
-	}
+// GetSubdomainConfigWithContext is GetSubdomainConfig with the config service call bounded by ctx.
+func (c *DefaultConfigClient) GetSubdomainConfigWithContext(ctx context.Context, publisherNamespace string) (*CORSSubdomainConfig, error) {
+	requestURL := fmt.Sprintf("%s/v1/admin/namespaces/%s/configs/CORS_SUBDOMAIN", c.baseURL, url.PathEscape(publisherNamespace))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, CORSSubdomainConfigKey, requestURL)
 	if err != nil {
 		return nil, newConfigFetchError(publisherNamespace, err)
 	}
@@ -163,41 +253,86 @@ func (c *DefaultConfigClient) GetSubdomainConfig(publisherNamespace string) (*CO
 	return &config, nil
 }
 
-// fetchFromService fetches the CORS config from the justice-config-service.
-// This function is passed as the loader to ConfigCache, so it is only called on cache misses.
-// The API returns a nested JSON structure where the actual CORS config is in the "value" field as a JSON string.
-// If an IAMClient is configured, a bearer token is included in the request.
-func (c *DefaultConfigClient) fetchFromService(namespace string) (*CORSConfigValue, error) {
-	url := fmt.Sprintf("%s/v1/admin/namespaces/%s/configs/CORS?includeParentConfig=studio,publisher", c.baseURL, namespace)
+// CircuitState returns the state of the config service circuit breaker.
+func (c *DefaultConfigClient) CircuitState() CircuitState {
+	return c.breaker.State()
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, newConfigFetchError(namespace, err)
+// do sends a GET request for the config key to url through the circuit breaker.
+// Network errors, 5xx responses and fetch timeouts are failures; a call cancelled by its caller is not counted.
+func (c *DefaultConfigClient) do(ctx context.Context, key, url string) (*http.Response, error) {
+	start := time.Now()
+	if err := c.breaker.Allow(); err != nil {
+		c.metrics.ConfigFetch(key, FetchStatusCircuitOpen, time.Since(start))
+		return nil, err
 	}
 
-	if c.iamClient != nil {
-		token := c.iamClient.ClientToken()
-		if token != "" {
-			req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.doWithRetry(ctx, url)
+	c.metrics.ConfigFetch(key, fetchStatus(resp, err), time.Since(start))
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.breaker.Cancel()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		c.breaker.Failure()
+	default:
+		c.breaker.Success()
+	}
+
+	return resp, err
+}
+
+// doWithRetry sends a GET request to url, retrying network errors and 5xx responses with a jittered
+// exponential backoff. It stops as soon as ctx is done. The last 5xx response is returned as is.
+// If an IAMClient is configured, a bearer token is included in every attempt.
+func (c *DefaultConfigClient) doWithRetry(ctx context.Context, url string) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.iamClient != nil {
+			token := c.iamClient.ClientToken()
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		resp, err := c.httpClient.Do(req)
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || retry >= c.retry.maxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(c.retry.backoff(retry)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
 	}
+}
 
-	resp, err := c.httpClient.Do(req)
+// fetchFromService fetches the CORS config from the justice-config-service.
+// This function is passed as the loader to ConfigCache, so it is only called on cache misses.
+// The API returns a nested JSON structure where the actual CORS config is in the "value" field as a JSON string.
+func (c *DefaultConfigClient) fetchFromService(ctx context.Context, namespace string) (*CORSConfigValue, error) {
+	requestURL := fmt.Sprintf("%s/v1/admin/namespaces/%s/configs/CORS?includeParentConfig=studio,publisher", c.baseURL, url.PathEscape(namespace))
+
+	resp, err := c.do(ctx, CORSConfigKey, requestURL)
 	if err != nil {
 		return nil, newConfigFetchError(namespace, err)
 	}
 	defer resp.Body.Close()
-
 	// 404 means no config for this namespace — not an error, fallback to service defaults
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, nil
 	}
 
-	if resp.StatusCode >= http.StatusInternalServerError {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, newConfigFetchError(namespace, fmt.Errorf("http status %d: %s", resp.StatusCode, string(body)))
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, newConfigFetchError(namespace, fmt.Errorf("http status %d: %s", resp.StatusCode, string(body)))
@@ -218,6 +353,10 @@ func (c *DefaultConfigClient) fetchFromService(namespace string) (*CORSConfigVal
 	if err := json.Unmarshal([]byte(response.Value), &config); err != nil {
 		return nil, newConfigFetchError(namespace, fmt.Errorf("failed to decode CORS config value: %w", err))
 	}
+	// with includeParentConfig, a namespace without CORS config gets the config of its studio or publisher
+	if response.Namespace != namespace {
+		config.SourceNamespace = response.Namespace
+	}
 
 	return &config, nil
 }
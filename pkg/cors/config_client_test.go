@@ -15,9 +15,12 @@
 package cors
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -80,7 +83,7 @@ func TestConfigClientGetCORSConfig(t *testing.T) {
 		t.Errorf("Expected domain 'https://example.com', got %q", config.AllowedDomains[0])
 	}
 
-	if !config.CookiesAllowed {
+	if config.CookiesAllowed == nil || !*config.CookiesAllowed {
 		t.Error("CookiesAllowed should be true")
 	}
 
@@ -122,6 +125,31 @@ func TestConfigClientNotFound(t *testing.T) {
 	}
 }
 
+func TestConfigClientEscapesNamespace(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, 1*time.Hour, nil, TransportConfig{})
+	if _, err := client.GetCORSConfig("game1/../other?x=1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetSubdomainConfig("pub#1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"/v1/admin/namespaces/game1%2F..%2Fother%3Fx=1/configs/CORS",
+		"/v1/admin/namespaces/pub%231/configs/CORS_SUBDOMAIN",
+	}
+	if len(paths) != 2 || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Errorf("Expected paths %v, got %v", expected, paths)
+	}
+}
+
 func TestConfigClientServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -222,7 +250,7 @@ func TestCORSConfigValueJsonTags(t *testing.T) {
 		t.Errorf("Expected MaxAge 7200, got %d", config.MaxAge)
 	}
 
-	if !config.CookiesAllowed {
+	if config.CookiesAllowed == nil || !*config.CookiesAllowed {
 		t.Error("CookiesAllowed should be true")
 	}
 
@@ -265,3 +293,111 @@ func TestIAMClientTokenAuthentication(t *testing.T) {
 		t.Errorf("Expected Authorization header '%s', got '%s'", expectedAuth, authHeader)
 	}
 }
+
+func TestConfigClientRetriesServerError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(ConfigServiceResponse{Value: `{"allowed_domains": ["https://example.com"]}`})
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, 1*time.Minute, nil, TransportConfig{RetryBaseDelay: time.Millisecond})
+
+	config, err := client.GetCORSConfig("test-ns")
+	if err != nil {
+		t.Fatalf("Expected success after retries, got error: %v", err)
+	}
+	if config == nil || len(config.AllowedDomains) != 1 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestConfigClientRetriesAreBounded(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, 1*time.Minute, nil, TransportConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+	if _, err := client.GetCORSConfig("test-ns"); err == nil {
+		t.Fatal("Expected error when every attempt fails")
+	}
+	if attempts != 4 {
+		t.Errorf("Expected 1 attempt + 3 retries, got %d", attempts)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	client = NewConfigClientWithIAM(server.URL, 1*time.Minute, nil, TransportConfig{MaxRetries: -1})
+	if _, err := client.GetCORSConfig("test-ns"); err == nil {
+		t.Fatal("Expected error when retries are disabled")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt with retries disabled, got %d", attempts)
+	}
+}
+
+func TestConfigClientDoesNotRetryClientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, 1*time.Minute, nil, TransportConfig{RetryBaseDelay: time.Millisecond})
+	if _, err := client.GetCORSConfig("test-ns"); err == nil {
+		t.Fatal("Expected error for 403")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 4xx not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestConfigClientRetriesNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewConfigClientWithIAM(url, 1*time.Minute, nil, TransportConfig{RetryBaseDelay: time.Millisecond})
+	if _, err := client.GetCORSConfig("test-ns"); err == nil {
+		t.Fatal("Expected error when the config service is unreachable")
+	}
+}
+
+func TestConfigClientHonorsContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewConfigClientWithIAM(server.URL, 1*time.Minute, nil, TransportConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetCORSConfigWithContext(ctx, "test-ns")
+	if err == nil {
+		t.Fatal("Expected error when the context deadline is exceeded")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the call to stop at the deadline, took %v", elapsed)
+	}
+}
@@ -14,38 +14,154 @@
 
 package cors
 
+import "strings"
+
+// MergeStrategy tells MergeConfigsWithPolicy how a namespace config field is combined with the service config field.
+type MergeStrategy int
+
+const (
+	// MergeUnion adds the namespace list entries to the service ones and lets a set namespace scalar override
+	// the service one, this is the default
+	MergeUnion MergeStrategy = iota
+	// MergeReplace uses the namespace list instead of the service one when it is not empty.
+	// Scalars behave as with MergeUnion.
+	MergeReplace
+	// MergeRestrict only keeps the namespace list entries the service list allows, so a namespace can narrow
+	// the service setting but never widen it. A namespace can turn a boolean off but not on, and can only
+	// lower MaxAge.
+	MergeRestrict
+)
+
+// MergePolicy is the MergeStrategy of each CORSConfigValue field. The zero value merges every field with MergeUnion.
+type MergePolicy struct {
+	AllowedDomains        MergeStrategy
+	AllowedHeaders        MergeStrategy
+	AllowedMethods        MergeStrategy
+	ExposeHeaders         MergeStrategy
+	CookiesAllowed        MergeStrategy
+	PrivateNetworkAllowed MergeStrategy
+	MaxAge                MergeStrategy
+}
+
 // MergeConfigs merges service-level and namespace-level CORS configurations.
-// List fields are deduplicated and concatenated; scalar fields use the namespace value as override.
+// List fields are deduplicated and concatenated; scalar fields use the namespace value as override when it is set.
 //
 // If ns is nil, a copy of the service config is returned.
 // If both are nil, a zero-valued MergedCORSConfig is returned.
 func MergeConfigs(service, ns *CORSConfigValue) *MergedCORSConfig {
+	return MergeConfigsWithPolicy(service, ns, MergePolicy{})
+}
+
+// MergeConfigsWithPolicy is MergeConfigs with the fields merged according to policy.
+func MergeConfigsWithPolicy(service, ns *CORSConfigValue, policy MergePolicy) *MergedCORSConfig {
+	sources := []configSource{{ConfigContributor{Level: ConfigLevelService}, service}}
+	if service == nil {
+		service = &CORSConfigValue{}
+	}
 	if ns == nil {
 		// No namespace config, use service config
-		if service == nil {
-			return &MergedCORSConfig{}
+		ns = &CORSConfigValue{}
+	} else {
+		sources = append(sources, configSource{ConfigContributor{Level: ConfigLevelNamespace, Namespace: ns.SourceNamespace}, ns})
+	}
+
+	allowedDomains, denyAllOrigins := mergeDomains(policy.AllowedDomains, service.AllowedDomains, ns.AllowedDomains)
+	return &MergedCORSConfig{
+		AllowedDomains:        allowedDomains,
+		DenyAllOrigins:        denyAllOrigins,
+		AllowedHeaders:        mergeList(policy.AllowedHeaders, service.AllowedHeaders, ns.AllowedHeaders, strings.EqualFold),
+		AllowedMethods:        mergeList(policy.AllowedMethods, service.AllowedMethods, ns.AllowedMethods, equalString),
+		ExposeHeaders:         mergeList(policy.ExposeHeaders, service.ExposeHeaders, ns.ExposeHeaders, strings.EqualFold),
+		CookiesAllowed:        mergeBool(policy.CookiesAllowed, service.CookiesAllowed, ns.CookiesAllowed),
+		PrivateNetworkAllowed: mergeBool(policy.PrivateNetworkAllowed, service.PrivateNetworkAllowed, ns.PrivateNetworkAllowed),
+		MaxAge:                mergeMaxAge(policy.MaxAge, service.MaxAge, ns.MaxAge),
+		CrossOriginIsolation:  mergeIsolation(service.CrossOriginIsolation, ns.CrossOriginIsolation),
+		sources:               sources,
+	}
+}
+
+func equalString(a, b string) bool {
+	return a == b
+}
+
+// mergeList merges the list fields, entries are compared with equal when restricted.
+func mergeList(strategy MergeStrategy, service, ns []string, equal func(a, b string) bool) []string {
+	if len(ns) == 0 {
+		return dedup(service)
+	}
+
+	switch strategy {
+	case MergeReplace:
+		return dedup(ns)
+	case MergeRestrict:
+		var result []string
+		for _, item := range ns {
+			for _, allowed := range service {
+				if equal(item, allowed) {
+					result = append(result, item)
+					break
+				}
+			}
+		}
+		return dedup(result)
+	default:
+		return dedup(append(append([]string{}, service...), ns...))
+	}
+}
+
+// mergeDomains merges AllowedDomains. An empty service list allows every origin, so it restricts nothing.
+// Otherwise a namespace origin is kept if the service patterns match it, and a namespace pattern is kept
+// if the service has the same pattern. When no namespace entry is kept, it returns denyAll since an empty
+// list would allow every origin.
+func mergeDomains(strategy MergeStrategy, service, ns []string) (domains []string, denyAll bool) {
+	if strategy != MergeRestrict || len(ns) == 0 {
+		return mergeList(strategy, service, ns, equalString), false
+	}
+	if len(service) == 0 {
+		return dedup(ns), false
+	}
+
+	matcher := NewOriginMatcherSet(service)
+	var result []string
+	for _, domain := range ns {
+		if pm, err := Compile(domain); err == nil && pm.Type == PatternTypeExact && domain != "*" {
+			if matcher.MatchOrigin(domain) {
+				result = append(result, domain)
+			}
+			continue
 		}
-		return &MergedCORSConfig{
-			AllowedDomains: append([]string{}, service.AllowedDomains...),
-			AllowedHeaders: append([]string{}, service.AllowedHeaders...),
-			AllowedMethods: append([]string{}, service.AllowedMethods...),
-			ExposeHeaders:  append([]string{}, service.ExposeHeaders...),
-			CookiesAllowed: service.CookiesAllowed,
-			MaxAge:         service.MaxAge,
+		for _, allowed := range service {
+			if domain == allowed {
+				result = append(result, domain)
+				break
+			}
 		}
 	}
 
-	// Both service and namespace configs exist; merge them
-	result := &MergedCORSConfig{
-		AllowedDomains: dedup(append(service.AllowedDomains, ns.AllowedDomains...)),
-		AllowedHeaders: dedup(append(service.AllowedHeaders, ns.AllowedHeaders...)),
-		AllowedMethods: dedup(append(service.AllowedMethods, ns.AllowedMethods...)),
-		ExposeHeaders:  dedup(append(service.ExposeHeaders, ns.ExposeHeaders...)),
-		CookiesAllowed: ns.CookiesAllowed, // namespace override
-		MaxAge:         ns.MaxAge,          // namespace override (0 = not set, use namespace's value)
+	return dedup(result), len(result) == 0
+}
+
+// mergeBool resolves the tri-state boolean fields, unset is false.
+func mergeBool(strategy MergeStrategy, service, ns *bool) bool {
+	serviceValue := service != nil && *service
+	if ns == nil {
+		return serviceValue
+	}
+	if strategy == MergeRestrict {
+		return serviceValue && *ns
 	}
+	return *ns
+}
 
-	return result
+// mergeMaxAge merges MaxAge, 0 means not set.
+func mergeMaxAge(strategy MergeStrategy, service, ns int) int {
+	if ns <= 0 {
+		return service
+	}
+	if strategy == MergeRestrict && (service <= 0 || ns > service) {
+		return service
+	}
+	return ns
 }
 
 // dedup removes duplicate strings from a slice while preserving order.
@@ -15,7 +15,13 @@
 package cors
 
 import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+
+	"github.com/emicklei/go-restful/v3"
 )
 
 func TestMergeConfigs_NilNamespaceConfig(t *testing.T) {
@@ -24,7 +30,7 @@ func TestMergeConfigs_NilNamespaceConfig(t *testing.T) {
 		AllowedHeaders: []string{"Content-Type", "Authorization"},
 		AllowedMethods: []string{"GET", "POST"},
 		ExposeHeaders:  []string{"X-Custom-Header"},
-		CookiesAllowed: true,
+		CookiesAllowed: Bool(true),
 		MaxAge:         3600,
 	}
 
@@ -77,11 +83,11 @@ func TestMergeConfigs_DeduplicateDomains(t *testing.T) {
 
 func TestMergeConfigs_NamespaceOverridesScalars(t *testing.T) {
 	service := &CORSConfigValue{
-		CookiesAllowed: false,
+		CookiesAllowed: Bool(false),
 		MaxAge:         3600,
 	}
 	namespace := &CORSConfigValue{
-		CookiesAllowed: true,
+		CookiesAllowed: Bool(true),
 		MaxAge:         7200,
 	}
 
@@ -119,7 +125,7 @@ func TestMergeConfigs_AllFields(t *testing.T) {
 		AllowedHeaders: []string{"Content-Type"},
 		AllowedMethods: []string{"GET"},
 		ExposeHeaders:  []string{"X-Service"},
-		CookiesAllowed: false,
+		CookiesAllowed: Bool(false),
 		MaxAge:         1000,
 	}
 	namespace := &CORSConfigValue{
@@ -127,7 +133,7 @@ func TestMergeConfigs_AllFields(t *testing.T) {
 		AllowedHeaders: []string{"Authorization"},
 		AllowedMethods: []string{"POST"},
 		ExposeHeaders:  []string{"X-Namespace"},
-		CookiesAllowed: true,
+		CookiesAllowed: Bool(true),
 		MaxAge:         2000,
 	}
 
@@ -155,3 +161,251 @@ func TestMergeConfigs_AllFields(t *testing.T) {
 		t.Errorf("MaxAge should be 2000 from namespace, got %d", result.MaxAge)
 	}
 }
+
+func TestMergeConfigs_PrivateNetworkAllowed(t *testing.T) {
+	service := &CORSConfigValue{PrivateNetworkAllowed: Bool(true)}
+
+	if !MergeConfigs(service, nil).PrivateNetworkAllowed {
+		t.Error("PrivateNetworkAllowed should be the service value without namespace config")
+	}
+	if !MergeConfigs(service, &CORSConfigValue{}).PrivateNetworkAllowed {
+		t.Error("PrivateNetworkAllowed should be the service value when the namespace doesn't set it")
+	}
+	if MergeConfigs(service, &CORSConfigValue{PrivateNetworkAllowed: Bool(false)}).PrivateNetworkAllowed {
+		t.Error("PrivateNetworkAllowed should be overridden by the namespace value")
+	}
+	if !MergeConfigs(&CORSConfigValue{}, &CORSConfigValue{PrivateNetworkAllowed: Bool(true)}).PrivateNetworkAllowed {
+		t.Error("PrivateNetworkAllowed should be enabled by the namespace value")
+	}
+}
+
+func TestMergeConfigs_CrossOriginIsolation(t *testing.T) {
+	if MergeConfigs(&CORSConfigValue{}, &CORSConfigValue{}).CrossOriginIsolation != nil {
+		t.Error("CrossOriginIsolation should be nil when no config sets it")
+	}
+
+	service := &CORSConfigValue{CrossOriginIsolation: &CrossOriginIsolationPolicy{OpenerPolicy: OpenerPolicySameOrigin, EmbedderPolicy: EmbedderPolicyRequireCorp}}
+	namespace := &CORSConfigValue{CrossOriginIsolation: &CrossOriginIsolationPolicy{EmbedderPolicy: EmbedderPolicyCredentialless}}
+	expected := CrossOriginIsolationPolicy{OpenerPolicy: OpenerPolicySameOrigin, EmbedderPolicy: EmbedderPolicyCredentialless}
+	if result := MergeConfigs(service, namespace).CrossOriginIsolation; result == nil || *result != expected {
+		t.Errorf("Expected the namespace policy over the service one, got %+v", result)
+	}
+}
+
+func TestMergeConfigs_UnsetScalarsKeepServiceValues(t *testing.T) {
+	service := &CORSConfigValue{CookiesAllowed: Bool(true), MaxAge: 3600}
+
+	// a namespace config omitting the scalars, as decoded from the config service
+	var namespace CORSConfigValue
+	if err := json.Unmarshal([]byte(`{"allowed_domains": ["https://ns.com"], "max_age": 0}`), &namespace); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := MergeConfigs(service, &namespace)
+	if !result.CookiesAllowed {
+		t.Error("CookiesAllowed should be the service value when the namespace doesn't set it")
+	}
+	if result.MaxAge != 3600 {
+		t.Errorf("MaxAge should be the service value when the namespace value is 0, got %d", result.MaxAge)
+	}
+
+	if err := json.Unmarshal([]byte(`{"cookies_allowed": false}`), &namespace); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if MergeConfigs(service, &namespace).CookiesAllowed {
+		t.Error("CookiesAllowed should be overridden by an explicit namespace false")
+	}
+}
+
+func TestMergeConfigsWithPolicy(t *testing.T) {
+	service := &CORSConfigValue{
+		AllowedDomains:        []string{"https://service.com", "https://*.studio.io", "re:^https://[a-z]+\\.tools\\.io$"},
+		AllowedHeaders:        []string{"Content-Type", "Authorization"},
+		AllowedMethods:        []string{"GET", "POST"},
+		ExposeHeaders:         []string{"X-Request-Id"},
+		CookiesAllowed:        Bool(true),
+		PrivateNetworkAllowed: Bool(false),
+		MaxAge:                600,
+	}
+	namespace := &CORSConfigValue{
+		AllowedDomains:        []string{"https://game.studio.io", "https://evil.com", "https://*.studio.io", "https://*.other.io", "*"},
+		AllowedHeaders:        []string{"authorization", "X-Secret"},
+		AllowedMethods:        []string{"POST", "DELETE"},
+		ExposeHeaders:         []string{"X-Namespace"},
+		CookiesAllowed:        Bool(false),
+		PrivateNetworkAllowed: Bool(true),
+		MaxAge:                7200,
+	}
+
+	tests := []struct {
+		name     string
+		policy   MergePolicy
+		expected *MergedCORSConfig
+	}{
+		{
+			name:   "union",
+			policy: MergePolicy{},
+			expected: &MergedCORSConfig{
+				AllowedDomains: []string{"https://service.com", "https://*.studio.io", "re:^https://[a-z]+\\.tools\\.io$",
+					"https://game.studio.io", "https://evil.com", "https://*.other.io", "*"},
+				AllowedHeaders:        []string{"Content-Type", "Authorization", "authorization", "X-Secret"},
+				AllowedMethods:        []string{"GET", "POST", "DELETE"},
+				ExposeHeaders:         []string{"X-Request-Id", "X-Namespace"},
+				CookiesAllowed:        false,
+				PrivateNetworkAllowed: true,
+				MaxAge:                7200,
+			},
+		},
+		{
+			name: "replace",
+			policy: MergePolicy{
+				AllowedDomains: MergeReplace, AllowedHeaders: MergeReplace, AllowedMethods: MergeReplace,
+				ExposeHeaders: MergeReplace, CookiesAllowed: MergeReplace, PrivateNetworkAllowed: MergeReplace, MaxAge: MergeReplace,
+			},
+			expected: &MergedCORSConfig{
+				AllowedDomains:        namespace.AllowedDomains,
+				AllowedHeaders:        namespace.AllowedHeaders,
+				AllowedMethods:        namespace.AllowedMethods,
+				ExposeHeaders:         namespace.ExposeHeaders,
+				CookiesAllowed:        false,
+				PrivateNetworkAllowed: true,
+				MaxAge:                7200,
+			},
+		},
+		{
+			name: "restrict",
+			policy: MergePolicy{
+				AllowedDomains: MergeRestrict, AllowedHeaders: MergeRestrict, AllowedMethods: MergeRestrict,
+				ExposeHeaders: MergeRestrict, CookiesAllowed: MergeRestrict, PrivateNetworkAllowed: MergeRestrict, MaxAge: MergeRestrict,
+			},
+			expected: &MergedCORSConfig{
+				AllowedDomains:        []string{"https://game.studio.io", "https://*.studio.io"},
+				AllowedHeaders:        []string{"authorization"},
+				AllowedMethods:        []string{"POST"},
+				ExposeHeaders:         []string{},
+				CookiesAllowed:        false,
+				PrivateNetworkAllowed: false,
+				MaxAge:                600,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MergeConfigsWithPolicy(service, namespace, tt.policy)
+			result.sources = nil // covered by the provenance tests
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMergeConfigsWithPolicy_RestrictNarrowsOnly(t *testing.T) {
+	policy := MergePolicy{CookiesAllowed: MergeRestrict, MaxAge: MergeRestrict, AllowedDomains: MergeRestrict}
+
+	result := MergeConfigsWithPolicy(
+		&CORSConfigValue{CookiesAllowed: Bool(false), MaxAge: 600},
+		&CORSConfigValue{CookiesAllowed: Bool(true), MaxAge: 60, AllowedDomains: []string{"https://ns.com"}},
+		policy)
+	if result.CookiesAllowed {
+		t.Error("A restricted namespace should not enable CookiesAllowed")
+	}
+	if result.MaxAge != 60 {
+		t.Errorf("A restricted namespace should lower MaxAge, got %d", result.MaxAge)
+	}
+	// an empty service AllowedDomains allows every origin
+	if len(result.AllowedDomains) != 1 || result.AllowedDomains[0] != "https://ns.com" {
+		t.Errorf("Expected the namespace domains to be kept, got %v", result.AllowedDomains)
+	}
+
+	result = MergeConfigsWithPolicy(&CORSConfigValue{}, &CORSConfigValue{MaxAge: 60}, policy)
+	if result.MaxAge != 0 {
+		t.Errorf("A restricted namespace should not set MaxAge when the service doesn't, got %d", result.MaxAge)
+	}
+}
+
+func TestMergeConfigsWithPolicy_RestrictDisjointDeniesAll(t *testing.T) {
+	policy := MergePolicy{AllowedDomains: MergeRestrict}
+
+	result := MergeConfigsWithPolicy(
+		&CORSConfigValue{AllowedDomains: []string{"https://a.com"}},
+		&CORSConfigValue{AllowedDomains: []string{"https://evil.com"}},
+		policy)
+	if !result.DenyAllOrigins {
+		t.Errorf("Expected a restricted namespace keeping no domain to deny every origin, got %v", result.AllowedDomains)
+	}
+
+	result = MergeConfigsWithPolicy(
+		&CORSConfigValue{AllowedDomains: []string{"https://a.com"}},
+		&CORSConfigValue{AllowedDomains: []string{"https://a.com", "https://evil.com"}},
+		policy)
+	if result.DenyAllOrigins {
+		t.Error("A restricted namespace keeping a domain should not deny every origin")
+	}
+
+	result = MergeConfigsWithPolicy(&CORSConfigValue{AllowedDomains: []string{"https://a.com"}}, &CORSConfigValue{}, policy)
+	if result.DenyAllOrigins {
+		t.Error("A namespace without AllowedDomains should not deny every origin")
+	}
+}
+
+func TestFilterMergePolicy_RestrictDisjointDeniesAll(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://evil.com"}}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://a.com"},
+		ConfigClient:   mockClient,
+		MergePolicy:    MergePolicy{AllowedDomains: MergeRestrict},
+	}
+
+	httpReq := httptest.NewRequest("GET", "/", nil)
+	httpReq.Header.Set(namespaceHeader, "game1")
+	config := filter.getConfigWithDynamicResolution(restful.NewRequest(httpReq))
+
+	for _, origin := range []string{"https://evil.com", "https://a.com", "https://other.com"} {
+		if filter.isOriginAllowedWithConfig(config, origin) {
+			t.Errorf("Expected %s to be rejected when the namespace keeps no allowed domain", origin)
+		}
+	}
+	if inspection := filter.InspectNamespace(context.Background(), "game1"); !inspection.DenyAllOrigins {
+		t.Error("Expected the inspection to report that every origin is denied")
+	}
+}
+
+func TestMergeConfigs_DoesNotModifyService(t *testing.T) {
+	domains := make([]string, 1, 4)
+	domains[0] = "https://service.com"
+	service := &CORSConfigValue{AllowedDomains: domains}
+
+	MergeConfigs(service, &CORSConfigValue{AllowedDomains: []string{"https://a.com"}})
+	MergeConfigs(service, &CORSConfigValue{AllowedDomains: []string{"https://b.com"}})
+
+	if extended := domains[:2]; extended[1] != "" {
+		t.Errorf("Expected the service slice backing array to be left untouched, got %v", extended)
+	}
+}
+
+func TestFilterMergePolicy(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{
+		AllowedDomains: []string{"https://game1.studio.io", "https://evil.com"},
+		CookiesAllowed: Bool(true),
+	}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://*.studio.io"},
+		ConfigClient:   mockClient,
+		MergePolicy:    MergePolicy{AllowedDomains: MergeRestrict, CookiesAllowed: MergeRestrict},
+	}
+
+	httpReq := httptest.NewRequest("GET", "/", nil)
+	httpReq.Header.Set(namespaceHeader, "game1")
+	config := filter.getConfigWithDynamicResolution(restful.NewRequest(httpReq))
+
+	if !reflect.DeepEqual(config.AllowedDomains, []string{"https://game1.studio.io"}) {
+		t.Errorf("Expected the namespace domains to be restricted by the service, got %v", config.AllowedDomains)
+	}
+	if config.CookiesAllowed {
+		t.Error("Expected the namespace not to enable CookiesAllowed")
+	}
+}
@@ -627,3 +627,57 @@ func TestFilterInitWithIAMClient(t *testing.T) {
 		t.Error("ConfigClient should be initialized")
 	}
 }
+
+// contextMockConfigClient blocks until the fetch context is done.
+type contextMockConfigClient struct {
+	MockConfigClient
+	deadline time.Duration
+}
+
+func (m *contextMockConfigClient) GetCORSConfigWithContext(ctx context.Context, _ string) (*CORSConfigValue, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		m.deadline = time.Until(deadline)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *contextMockConfigClient) GetSubdomainConfigWithContext(_ context.Context, _ string) (*CORSSubdomainConfig, error) {
+	return nil, nil
+}
+
+func TestFilterHonorsConfigFetchTimeout(t *testing.T) {
+	mockClient := &contextMockConfigClient{MockConfigClient: *NewMockConfigClient()}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:     []string{"https://static.com"},
+		AllowedMethods:     []string{"GET"},
+		ConfigClient:       mockClient,
+		ConfigFetchTimeout: 30 * time.Millisecond,
+	}
+
+	httpReq := httptest.NewRequest("GET", "/", nil)
+	httpReq.Header.Set(restful.HEADER_Origin, "https://static.com")
+	httpReq.Header.Set(namespaceHeader, "slow-ns")
+	req := restful.NewRequest(httpReq)
+	recorder := httptest.NewRecorder()
+	called := false
+
+	start := time.Now()
+	filter.Filter(req, restful.NewResponse(recorder), createTestFilterChain(&called))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the config fetch to stop after ConfigFetchTimeout, took %v", elapsed)
+	}
+	if mockClient.deadline <= 0 || mockClient.deadline > 30*time.Millisecond {
+		t.Errorf("Expected a fetch deadline of at most 30ms, got %v", mockClient.deadline)
+	}
+	if !called {
+		t.Error("Expected the request to continue with the static config")
+	}
+	if recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin) != "https://static.com" {
+		t.Error("Expected the static config to be applied after the timeout")
+	}
+	if req.Request.Context().Err() != nil {
+		t.Error("Expected the request context to stay usable")
+	}
+}
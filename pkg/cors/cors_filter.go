@@ -15,13 +15,18 @@
 package cors
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/hostmap"
 	iam "github.com/AccelByte/iam-go-sdk/v2"
+	"github.com/bluele/gcache"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/sirupsen/logrus"
 )
@@ -42,18 +47,80 @@ type CrossOriginResourceSharing struct {
 	CookiesAllowed bool
 	Container      *restful.Container
 
+	// PreflightStatus is the status of allowed preflight responses (default 204 No Content).
+	// Disallowed preflights get it too, without CORS headers, unless RejectDisallowedPreflights is set.
+	PreflightStatus int
+	// RejectDisallowedPreflights responds 403 Forbidden to preflights whose origin, method or headers are not allowed.
+	RejectDisallowedPreflights bool
+
+	// PrivateNetworkAllowed answers Private Network Access preflights (Access-Control-Request-Private-Network: true)
+	// with Access-Control-Allow-Private-Network: true, so pages on public origins can call services on a private network.
+	PrivateNetworkAllowed bool
+
 	// Dynamic CORS config support (optional - if ConfigServiceURL is set, dynamic config is enabled)
-	ConfigServiceURL   string        // Base URL of justice-config-service (e.g. "http://justice-config-service/config"). If empty, static config is used.
-	ConfigClient       ConfigClient  // Client for fetching namespace-scoped CORS config (set automatically from ConfigServiceURL on first request)
-	ConfigFetchTimeout time.Duration // Per-request timeout for config service calls (default 200ms)
-	IAMClient          iam.Client    // IAM client for obtaining bearer tokens to authenticate config service requests (optional)
-	PublisherNamespace string        // Publisher namespace used to fetch subdomain extraction settings (CORS_SUBDOMAIN config key)
+	ConfigServiceURL   string             // Base URL of justice-config-service (e.g. "http://justice-config-service/config"). If empty, static config is used.
+	ConfigClient       ConfigClient       // Client for fetching namespace-scoped CORS config (set automatically from ConfigServiceURL on first request)
+	ConfigFetchTimeout time.Duration      // Per-request timeout for config service calls (default 200ms), enforced when ConfigClient implements ContextConfigClient
+	ConfigCacheOptions ConfigCacheOptions // Cache of the namespace configs fetched from ConfigServiceURL (default TTL 1 minute, StaleTTL 10 minutes, ErrorTTL 5 seconds; negative disables)
+	IAMClient          iam.Client         // IAM client for obtaining bearer tokens to authenticate config service requests (optional)
+	PublisherNamespace string             // Publisher namespace used to fetch subdomain extraction settings (CORS_SUBDOMAIN config key)
+	TransportConfig    TransportConfig    // HTTP transport, retries and circuit breaker of the client created from ConfigServiceURL
+
+	// WarmUpNamespaces are prefetched by Start, so their first requests don't wait for the config service
+	WarmUpNamespaces []string
+
+	// NamespaceResolver resolves the namespace of the requests (default DefaultNamespaceResolver). Requests without
+	// namespace use the PublisherNamespace config.
+	NamespaceResolver NamespaceResolver
+
+	// HostMapping maps the request hosts to namespaces for DefaultNamespaceResolver, e.g. multi-level subdomains
+	// like ns.dev.accelbyte.io. It can be shared with the IAM filter subdomain validation.
+	HostMapping *hostmap.Table
+
+	// MergePolicy tells how each field of the namespace configs is merged with the service config (default union),
+	// e.g. MergeRestrict forbids namespaces from widening a setting
+	MergePolicy MergePolicy
+
+	// the ConfigClient and the static config are created once, by Init
+	initOnce sync.Once
 
 	// subdomain config is fetched lazily from the config service on first request and refreshed every subdomainConfigTTL
 	subdomainMu       sync.Mutex
 	subdomainConfig   *CORSSubdomainConfig
+	customDomains     hostmap.StaticRegistry // the normalized CustomDomains of subdomainConfig
 	subdomainLoaded   bool
 	subdomainLoadedAt time.Time
+
+	// merged namespace configs with their precompiled origin matcher, reused while the ConfigClient
+	// returns the same cached *CORSConfigValue. Bounded like the config cache, see ConfigCacheOptions.Size.
+	mergedConfigsOnce sync.Once
+	mergedConfigs     gcache.Cache // namespace -> *mergedConfigEntry
+
+	// WebServicePolicies are CORS policies keyed by WebService root path, merged over the service and namespace
+	// configs for the routes of the WebService. Route policies are declared with RoutePolicy.
+	WebServicePolicies map[string]*CORSConfigValue
+
+	// Diagnostics records why cross-origin requests are rejected (optional)
+	Diagnostics Diagnostics
+
+	// Metrics counts the allowed and rejected origins and the static config fallbacks (optional).
+	// The client created from ConfigServiceURL reports its cache and config service calls to it too,
+	// unless ConfigCacheOptions.Metrics or TransportConfig.Metrics are set.
+	Metrics Metrics
+
+	// static config with its precompiled AllowedDomains and precompiled Diagnostics.DebugOrigins, built by Init
+	staticConfig *MergedCORSConfig
+	debugMatcher *OriginMatcherSet
+
+	// origin matchers of the configs merged with route policies
+	routeMatchersOnce sync.Once
+	routeMatchers     gcache.Cache
+}
+
+// mergedConfigEntry is the merged config computed from a ConfigClient entry.
+type mergedConfigEntry struct {
+	source *CORSConfigValue
+	config *MergedCORSConfig
 }
 
 // NewCrossOriginResourceSharing creates a new CORS filter with service-level default configuration.
@@ -97,46 +164,135 @@ func NewCrossOriginResourceSharing(
 	}, nil
 }
 
+// NewCrossOriginResourceSharingWithConfigClient is NewCrossOriginResourceSharing with the namespace configs
+// fetched from configClient instead of justice-config-service, e.g. a FileConfigClient, an EnvConfigClient
+// or a ChainedConfigClient. A nil configClient uses the static config only.
+func NewCrossOriginResourceSharingWithConfigClient(
+	configClient ConfigClient,
+	publisherNamespace string,
+	allowedDomains []string,
+	allowedMethods []string,
+	allowedHeaders []string,
+	exposeHeaders []string,
+	cookiesAllowed bool,
+	maxAge int,
+) *CrossOriginResourceSharing {
+	return &CrossOriginResourceSharing{
+		ConfigClient:       configClient,
+		AllowedDomains:     allowedDomains,
+		AllowedMethods:     allowedMethods,
+		AllowedHeaders:     allowedHeaders,
+		ExposeHeaders:      exposeHeaders,
+		CookiesAllowed:     cookiesAllowed,
+		MaxAge:             maxAge,
+		PublisherNamespace: publisherNamespace,
+	}
+}
+
 // Filter is a filter function that implements the CORS flow
 func (c *CrossOriginResourceSharing) Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	origin := req.Request.Header.Get(restful.HEADER_Origin)
+	preflight := c.isPreflightRequest(req)
+
+	// the response depends on the request origin even when it has none, so a cached same-origin response
+	// is never served to a cross-origin request
+	if preflight {
+		addVary(resp, restful.HEADER_Origin, restful.HEADER_AccessControlRequestMethod, restful.HEADER_AccessControlRequestHeaders)
+	} else {
+		addVary(resp, restful.HEADER_Origin)
+	}
+
 	if len(origin) == 0 {
 		chain.ProcessFilter(req, resp)
 		return
 	}
 
-	// Initialize config client on first request if not already set
-	if c.ConfigClient == nil {
-		c.initConfigServiceClient()
-	}
+	// Initialize config client on first request if Init wasn't called
+	c.Init()
 
 	// Try to fetch dynamic config if ConfigClient is available
 	var config *MergedCORSConfig
+	decision := Decision{Origin: origin, Preflight: preflight, Source: ConfigSourceStatic}
 	if c.ConfigClient != nil {
-		config = c.getConfigWithDynamicResolution(req)
+		config, decision.Namespace, decision.Source = c.resolveDynamicConfig(req)
 	}
 
 	// Fall back to static config if dynamic resolution failed or is not available
 	if config == nil {
 		config = c.getStaticConfig()
 	}
+	config = c.applyRoutePolicies(req, preflight, config)
 
 	if !c.isOriginAllowedWithConfig(config, origin) {
-		logrus.Debugf("HTTP Origin:%s is not part of %v", origin, config.AllowedDomains)
+		decision.Reason = RejectionOriginNotAllowed
+		c.reportDecision(req, resp, decision)
+		if preflight && c.RejectDisallowedPreflights {
+			resp.WriteHeader(http.StatusForbidden)
+			return
+		}
 		chain.ProcessFilter(req, resp)
 		return
 	}
 
-	if c.isPreflightRequest(req) {
-		c.doPreflightRequestWithConfig(req, resp, config)
-		// return http 200 response, no body
+	if preflight {
+		decision.Reason, decision.Detail = c.doPreflightRequestWithConfig(req, resp, config)
+		c.reportDecision(req, resp, decision)
+		if !decision.Allowed() && c.RejectDisallowedPreflights {
+			resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+		// no body
+		resp.WriteHeader(c.preflightStatus())
 		return
 	}
 
+	c.reportDecision(req, resp, decision)
 	c.setOptionsHeadersWithConfig(req, resp, config)
 	chain.ProcessFilter(req, resp)
 }
 
+// defaultPreflightStatus is the status of preflight responses when PreflightStatus is not set.
+const defaultPreflightStatus = http.StatusNoContent
+
+func (c *CrossOriginResourceSharing) preflightStatus() int {
+	if c.PreflightStatus == 0 {
+		return defaultPreflightStatus
+	}
+	return c.PreflightStatus
+}
+
+const headerVary = "Vary"
+
+// addVary adds the header names to the Vary response header, skipping the ones already listed.
+func addVary(resp *restful.Response, names ...string) {
+	header := resp.Header()
+	listed := make(map[string]bool)
+	for _, value := range header.Values(headerVary) {
+		for _, name := range strings.Split(value, ",") {
+			listed[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+	if listed["*"] {
+		return
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !listed[strings.ToLower(name)] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		header.Add(headerVary, strings.Join(missing, ", "))
+	}
+}
+
+// Private Network Access headers, see https://wicg.github.io/private-network-access/
+const (
+	HeaderAccessControlRequestPrivateNetwork = "Access-Control-Request-Private-Network"
+	HeaderAccessControlAllowPrivateNetwork   = "Access-Control-Allow-Private-Network"
+)
+
 // isPreflightRequest will check if the request is a preflight request or not.
 func (c *CrossOriginResourceSharing) isPreflightRequest(req *restful.Request) bool {
 	if req.Request.Method == "OPTIONS" {
@@ -147,6 +303,25 @@ func (c *CrossOriginResourceSharing) isPreflightRequest(req *restful.Request) bo
 	return false
 }
 
+// Defaults of the cache of the namespace configs fetched from ConfigServiceURL.
+const (
+	defaultConfigCacheTTL = time.Minute      // how long a config is fresh
+	defaultConfigStaleTTL = 10 * time.Minute // how long an expired config is served while it is refreshed
+	defaultConfigErrorTTL = 5 * time.Second  // how long a failed fetch is cached
+)
+
+// durationOrDefault returns value, or fallback when value is 0. Negative values disable the setting.
+func durationOrDefault(value, fallback time.Duration) time.Duration {
+	switch {
+	case value == 0:
+		return fallback
+	case value < 0:
+		return 0
+	default:
+		return value
+	}
+}
+
 // initConfigServiceClient initializes the config service client from ConfigServiceURL.
 // If ConfigServiceURL is empty, dynamic config is disabled and static config is used.
 // IAMClient is required when ConfigServiceURL is set; initialization is skipped with an error log if missing.
@@ -162,20 +337,94 @@ func (c *CrossOriginResourceSharing) initConfigServiceClient() {
 		return
 	}
 
-	c.ConfigClient = NewConfigClientWithIAM(c.ConfigServiceURL, 1*time.Minute, c.IAMClient, TransportConfig{})
+	cacheOptions := c.ConfigCacheOptions
+	if cacheOptions.TTL == 0 {
+		cacheOptions.TTL = defaultConfigCacheTTL
+	}
+	cacheOptions.StaleTTL = durationOrDefault(cacheOptions.StaleTTL, defaultConfigStaleTTL)
+	cacheOptions.ErrorTTL = durationOrDefault(cacheOptions.ErrorTTL, defaultConfigErrorTTL)
+	// the namespace configs inherit CookiesAllowed, so a "*" origin is unsafe even without cookies_allowed
+	validator := NewConfigValidatorFromEnv()
+	if cacheOptions.Validator != nil {
+		copied := *cacheOptions.Validator
+		validator = &copied
+	}
+	validator.InheritedCookiesAllowed = c.CookiesAllowed
+	cacheOptions.Validator = validator
+	if cacheOptions.LoadTimeout == 0 {
+		cacheOptions.LoadTimeout = c.ConfigFetchTimeout
+		if cacheOptions.LoadTimeout <= 0 {
+			cacheOptions.LoadTimeout = defaultConfigFetchTimeout
+		}
+	}
+	if cacheOptions.Metrics == nil {
+		cacheOptions.Metrics = c.Metrics
+	}
+	transportConfig := c.TransportConfig
+	if transportConfig.Metrics == nil {
+		transportConfig.Metrics = c.Metrics
+	}
+	c.ConfigClient = NewConfigClientWithCacheOptions(c.ConfigServiceURL, cacheOptions, c.IAMClient, transportConfig)
 	logrus.Infof("Initialized CORS config service client with URL: %s", c.ConfigServiceURL)
 }
 
-// getStaticConfig returns a MergedCORSConfig from the service-level static configuration.
+// getStaticConfig returns the MergedCORSConfig of the service-level static configuration, built by Init.
 func (c *CrossOriginResourceSharing) getStaticConfig() *MergedCORSConfig {
+	c.Init()
+	return c.staticConfig
+}
+
+// newStaticConfig builds the MergedCORSConfig of the service-level static configuration,
+// with its precompiled AllowedDomains.
+func (c *CrossOriginResourceSharing) newStaticConfig() *MergedCORSConfig {
 	return &MergedCORSConfig{
-		AllowedDomains: c.AllowedDomains,
-		AllowedHeaders: c.AllowedHeaders,
-		AllowedMethods: c.AllowedMethods,
-		ExposeHeaders:  c.ExposeHeaders,
-		CookiesAllowed: c.CookiesAllowed,
-		MaxAge:         c.MaxAge,
+		AllowedDomains:        c.AllowedDomains,
+		AllowedHeaders:        c.AllowedHeaders,
+		AllowedMethods:        c.AllowedMethods,
+		ExposeHeaders:         c.ExposeHeaders,
+		CookiesAllowed:        c.CookiesAllowed,
+		PrivateNetworkAllowed: c.PrivateNetworkAllowed,
+		MaxAge:                c.MaxAge,
+		originMatcher:         NewOriginMatcherSet(c.AllowedDomains),
+	}
+}
+
+// defaultConfigFetchTimeout bounds config service calls when ConfigFetchTimeout is not set.
+const defaultConfigFetchTimeout = 200 * time.Millisecond
+
+// fetchContext returns a context bounded by ConfigFetchTimeout, derived from parent.
+// The request context itself is never replaced, so cancelling the returned context doesn't affect
+// downstream handlers.
+func (c *CrossOriginResourceSharing) fetchContext(parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := c.ConfigFetchTimeout
+	if timeout <= 0 {
+		timeout = defaultConfigFetchTimeout
 	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// getCORSConfig fetches the namespace config, bounded by ConfigFetchTimeout when the client supports contexts.
+func (c *CrossOriginResourceSharing) getCORSConfig(parent context.Context, namespace string) (*CORSConfigValue, error) {
+	client, ok := c.ConfigClient.(ContextConfigClient)
+	if !ok {
+		return c.ConfigClient.GetCORSConfig(namespace)
+	}
+
+	ctx, cancel := c.fetchContext(parent)
+	defer cancel()
+	return client.GetCORSConfigWithContext(ctx, namespace)
+}
+
+// getSubdomainConfig fetches the subdomain config, bounded by ConfigFetchTimeout when the client supports contexts.
+func (c *CrossOriginResourceSharing) getSubdomainConfig(parent context.Context, publisherNamespace string) (*CORSSubdomainConfig, error) {
+	client, ok := c.ConfigClient.(ContextConfigClient)
+	if !ok {
+		return c.ConfigClient.GetSubdomainConfig(publisherNamespace)
+	}
+
+	ctx, cancel := c.fetchContext(parent)
+	defer cancel()
+	return client.GetSubdomainConfigWithContext(ctx, publisherNamespace)
 }
 
 // subdomainConfigTTL is the duration for which the fetched subdomain config is considered fresh.
@@ -185,7 +434,7 @@ const subdomainConfigTTL = time.Hour
 // The result is cached for subdomainConfigTTL; on expiry the next request triggers a refresh.
 // On failure the existing cached value is kept and subdomainLoadedAt is not updated, so the
 // next request will retry immediately.
-func (c *CrossOriginResourceSharing) loadSubdomainConfig() {
+func (c *CrossOriginResourceSharing) loadSubdomainConfig(ctx context.Context) {
 	if c.PublisherNamespace == "" {
 		return
 	}
@@ -194,12 +443,25 @@ func (c *CrossOriginResourceSharing) loadSubdomainConfig() {
 	if c.subdomainLoaded && time.Since(c.subdomainLoadedAt) < subdomainConfigTTL {
 		return
 	}
-	cfg, err := c.ConfigClient.GetSubdomainConfig(c.PublisherNamespace)
+	cfg, err := c.getSubdomainConfig(ctx, c.PublisherNamespace)
+	if errors.Is(err, ErrCircuitOpen) {
+		logrus.Debugf("cors: skipped fetching subdomain config for publisher namespace %q: %v", c.PublisherNamespace, err)
+		return
+	}
 	if err != nil {
 		logrus.Errorf("cors: failed to fetch subdomain config for publisher namespace %q: %v", c.PublisherNamespace, err)
 		return
 	}
+	c.setSubdomainConfig(cfg)
+}
+
+// setSubdomainConfig caches cfg for subdomainConfigTTL, the caller holds subdomainMu.
+func (c *CrossOriginResourceSharing) setSubdomainConfig(cfg *CORSSubdomainConfig) {
 	c.subdomainConfig = cfg // nil is valid: means no config, subdomain extraction disabled
+	c.customDomains = nil
+	if cfg != nil {
+		c.customDomains = hostmap.NewStaticRegistry(cfg.CustomDomains)
+	}
 	c.subdomainLoaded = true
 	c.subdomainLoadedAt = time.Now()
 }
@@ -215,83 +477,185 @@ func (c *CrossOriginResourceSharing) getSubdomainSettings() (enabled bool, baseD
 	return cfg.SubdomainEnabled, cfg.SubdomainBaseDomain
 }
 
+// lookupCustomDomain returns the namespace of host from the CustomDomains of the cached publisher namespace config.
+func (c *CrossOriginResourceSharing) lookupCustomDomain(host string) (string, bool) {
+	c.subdomainMu.Lock()
+	customDomains := c.customDomains
+	c.subdomainMu.Unlock()
+	return customDomains.LookupHost(hostmap.NormalizeHost(host))
+}
+
+// CustomDomainRegistry returns the custom domains of the CORS_SUBDOMAIN config of PublisherNamespace as a
+// hostmap.Registry, e.g. to share them with the IAM filter subdomain validation through a hostmap.Table.
+// The config is fetched and refreshed like for the requests.
+func (c *CrossOriginResourceSharing) CustomDomainRegistry() hostmap.Registry {
+	return hostmap.RegistryFunc(func(host string) (string, bool) {
+		c.Init()
+		if c.ConfigClient == nil {
+			return "", false
+		}
+		c.loadSubdomainConfig(context.Background())
+		return c.lookupCustomDomain(host)
+	})
+}
+
+// DefaultNamespaceResolver returns the resolver used when NamespaceResolver is not set: the "namespace" path
+// parameter, then the host mapped by HostMapping, then the custom domains of the CORS_SUBDOMAIN config of
+// PublisherNamespace, then the subdomain when enabled by that config, then the x-ab-rl-ns header.
+// See ExtractNamespace.
+func (c *CrossOriginResourceSharing) DefaultNamespaceResolver() NamespaceResolver {
+	return NamespaceResolverFunc(c.resolveDefaultNamespace)
+}
+
+func (c *CrossOriginResourceSharing) resolveDefaultNamespace(req *restful.Request) string {
+	c.loadSubdomainConfig(req.Request.Context())
+	if ns := req.PathParameter("namespace"); ns != "" {
+		return ns
+	}
+	if ns, ok := c.HostMapping.Namespace(req.Request.Host); ok {
+		return ns
+	}
+	if ns, ok := c.lookupCustomDomain(req.Request.Host); ok {
+		return ns
+	}
+	subdomainEnabled, baseDomain := c.getSubdomainSettings()
+	return ExtractNamespace(req, subdomainEnabled, baseDomain)
+}
+
 // getConfigWithDynamicResolution attempts to fetch and merge namespace-scoped config with static config.
 // Returns nil if dynamic resolution fails (fallback to static config).
 func (c *CrossOriginResourceSharing) getConfigWithDynamicResolution(req *restful.Request) *MergedCORSConfig {
-	c.loadSubdomainConfig()
-	subdomainEnabled, baseDomain := c.getSubdomainSettings()
-	namespace := ExtractNamespace(req, subdomainEnabled, baseDomain)
+	config, _, _ := c.resolveDynamicConfig(req)
+	return config
+}
+
+// resolveDynamicConfig is getConfigWithDynamicResolution returning the resolved namespace and the config source.
+func (c *CrossOriginResourceSharing) resolveDynamicConfig(req *restful.Request) (*MergedCORSConfig, string, ConfigSource) {
+	resolver := c.NamespaceResolver
+	if resolver == nil {
+		resolver = c.DefaultNamespaceResolver()
+	}
+	namespace := resolver.ResolveNamespace(req)
 	if namespace == "" && c.PublisherNamespace != "" {
 		namespace = c.PublisherNamespace
 	}
 	if namespace == "" {
-		return nil
+		return nil, "", ConfigSourceStatic
 	}
 
-	namespaceConfig, err := c.ConfigClient.GetCORSConfig(namespace)
-	if err != nil {
+	config, source, err := c.getNamespaceConfig(req.Request.Context(), namespace)
+	if errors.Is(err, ErrCircuitOpen) {
+		logrus.Debugf("Skipped fetching CORS config for namespace %s: %v", namespace, err)
+	} else if err != nil {
 		logrus.Errorf("Failed to fetch CORS config for namespace %s: %v", namespace, err)
-		return nil
+	}
+	if err != nil && c.Metrics != nil {
+		c.Metrics.StaticFallback(namespace)
+	}
+
+	return config, namespace, source
+}
+
+// getNamespaceConfig returns the service config merged with the config of namespace.
+// On failure it returns a nil config with ConfigSourceFallback and the error.
+func (c *CrossOriginResourceSharing) getNamespaceConfig(ctx context.Context, namespace string) (*MergedCORSConfig, ConfigSource, error) {
+	namespaceConfig, err := c.getCORSConfig(ctx, namespace)
+	if err != nil {
+		return nil, ConfigSourceFallback, err
+	}
+
+	if namespaceConfig == nil {
+		// no namespace config, the merge result is the static config
+		return c.getStaticConfig(), ConfigSourceStatic, nil
+	}
+
+	// The ConfigClient returns the same pointer while its cache entry is fresh,
+	// so the merged config and its compiled matcher are only rebuilt after a refresh.
+	if v, err := c.mergedConfigCache().Get(namespace); err == nil {
+		if entry := v.(*mergedConfigEntry); entry.source == namespaceConfig {
+			return entry.config, ConfigSourceNamespace, nil
+		}
 	}
 
 	// Merge service and namespace configs
-	serviceConfig := &CORSConfigValue{
-		AllowedDomains: c.AllowedDomains,
-		AllowedHeaders: c.AllowedHeaders,
-		AllowedMethods: c.AllowedMethods,
-		ExposeHeaders:  c.ExposeHeaders,
-		CookiesAllowed: c.CookiesAllowed,
-		MaxAge:         c.MaxAge,
+	serviceConfig := c.getStaticConfig().value()
+
+	config := MergeConfigsWithPolicy(serviceConfig, namespaceConfig, c.MergePolicy)
+	config.originMatcher = NewOriginMatcherSet(config.AllowedDomains)
+	config.sources = []configSource{
+		{ConfigContributor{Level: ConfigLevelService}, serviceConfig},
+		{c.namespaceContributor(namespace, namespaceConfig), namespaceConfig},
 	}
+	_ = c.mergedConfigCache().Set(namespace, &mergedConfigEntry{source: namespaceConfig, config: config})
 
-	return MergeConfigs(serviceConfig, namespaceConfig)
+	return config, ConfigSourceNamespace, nil
+}
+
+// mergedConfigCache returns the LRU cache of the merged namespace configs, with the size of the config cache.
+func (c *CrossOriginResourceSharing) mergedConfigCache() gcache.Cache {
+	c.mergedConfigsOnce.Do(func() {
+		size := c.ConfigCacheOptions.Size
+		if size <= 0 {
+			size = defaultCacheSize
+		}
+		c.mergedConfigs = gcache.New(size).LRU().Build()
+	})
+	return c.mergedConfigs
 }
 
 // isOriginAllowedWithConfig checks if origin is allowed according to the provided config.
+// The precompiled matcher of the config is used when available, no origin is allowed with DenyAllOrigins.
 func (c *CrossOriginResourceSharing) isOriginAllowedWithConfig(config *MergedCORSConfig, origin string) bool {
-	if len(origin) == 0 {
+	if config.DenyAllOrigins {
 		return false
 	}
-	if len(config.AllowedDomains) == 0 {
-		return true
+	matcher := config.originMatcher
+	if matcher == nil {
+		matcher = NewOriginMatcherSet(config.AllowedDomains)
 	}
 
-	for _, domain := range config.AllowedDomains {
-		pm, err := Compile(domain)
-		if err != nil {
-			logrus.Debugf("Invalid CORS domain pattern %q: %v", domain, err)
-			continue
-		}
-		if pm.MatchOrigin(origin) {
-			return true
-		}
-	}
+	return matcher.MatchOrigin(origin)
+}
 
-	return false
+// doPreflightRequestWithConfig sets the preflight response headers when the preflight request is allowed
+// by the merged config, and returns why it is rejected otherwise, with the rejected method or header.
+func (c *CrossOriginResourceSharing) doPreflightRequestWithConfig(req *restful.Request, resp *restful.Response, config *MergedCORSConfig) (RejectionReason, string) {
+	reason, detail := c.checkPreflightRequestWithConfig(req, config)
+	if reason == RejectionNone {
+		c.writePreflightHeadersWithConfig(req, resp, config)
+	}
+	return reason, detail
 }
 
-// doPreflightRequestWithConfig handles preflight requests with the merged config.
-func (c *CrossOriginResourceSharing) doPreflightRequestWithConfig(req *restful.Request, resp *restful.Response, config *MergedCORSConfig) {
+// checkPreflightRequestWithConfig returns why the preflight request is rejected, with the rejected method or header.
+func (c *CrossOriginResourceSharing) checkPreflightRequestWithConfig(req *restful.Request, config *MergedCORSConfig) (RejectionReason, string) {
 	acrm := req.Request.Header.Get(restful.HEADER_AccessControlRequestMethod)
 	if !c.isValidAccessControlRequestMethodWithConfig(config, acrm) {
 		logrus.Debugf("Http header %s:%s is not in %v",
 			restful.HEADER_AccessControlRequestMethod,
 			acrm,
 			config.AllowedMethods)
-		return
+		return RejectionMethodNotAllowed, acrm
 	}
 	acrhs := req.Request.Header.Get(restful.HEADER_AccessControlRequestHeaders)
 	if len(acrhs) > 0 {
 		for _, each := range strings.Split(acrhs, ",") {
-			if !c.isValidAccessControlRequestHeaderWithConfig(config, strings.Trim(each, " ")) {
+			header := strings.Trim(each, " ")
+			if !c.isValidAccessControlRequestHeaderWithConfig(config, header) {
 				logrus.Debugf("Http header %s:%s is not in %v",
 					restful.HEADER_AccessControlRequestHeaders,
 					acrhs,
 					config.AllowedHeaders)
-				return
+				return RejectionHeaderNotAllowed, header
 			}
 		}
 	}
+	return RejectionNone, ""
+}
+
+// writePreflightHeadersWithConfig sets the preflight response headers of an allowed preflight request.
+func (c *CrossOriginResourceSharing) writePreflightHeadersWithConfig(req *restful.Request, resp *restful.Response, config *MergedCORSConfig) {
+	acrhs := req.Request.Header.Get(restful.HEADER_AccessControlRequestHeaders)
 	resp.AddHeader(restful.HEADER_AccessControlAllowMethods, strings.Join(config.AllowedMethods, ","))
 	resp.AddHeader(restful.HEADER_AccessControlAllowHeaders, acrhs)
 
@@ -299,6 +663,10 @@ func (c *CrossOriginResourceSharing) doPreflightRequestWithConfig(req *restful.R
 		resp.AddHeader(restful.HEADER_AccessControlMaxAge, strconv.Itoa(config.MaxAge))
 	}
 
+	if config.PrivateNetworkAllowed && req.Request.Header.Get(HeaderAccessControlRequestPrivateNetwork) == "true" {
+		resp.AddHeader(HeaderAccessControlAllowPrivateNetwork, "true")
+	}
+
 	c.setOptionsHeadersWithConfig(req, resp, config)
 }
 
@@ -250,6 +250,42 @@ func TestPreflightRequest_MaxAgeConfigured(t *testing.T) {
 	assert.Equal(t, "3600", resp1.Header().Get("Access-Control-Max-Age"))
 }
 
+func TestPreflightRequest_PrivateNetwork(t *testing.T) {
+	c := CrossOriginResourceSharing{}
+	config := &MergedCORSConfig{
+		AllowedMethods:        []string{"GET"},
+		PrivateNetworkAllowed: true,
+	}
+
+	// TEST 1: Private network requested and allowed
+	req1 := createDummyRequest()
+	req1.Request.Header.Set("Access-Control-Request-Method", "GET")
+	req1.Request.Header.Set("Access-Control-Request-Private-Network", "true")
+	resp1 := createDummyResponse()
+	c.doPreflightRequestWithConfig(req1, resp1, config)
+
+	assert.Equal(t, "true", resp1.Header().Get("Access-Control-Allow-Private-Network"))
+
+	// TEST 2: Private network not requested
+	req2 := createDummyRequest()
+	req2.Request.Header.Set("Access-Control-Request-Method", "GET")
+	resp2 := createDummyResponse()
+	c.doPreflightRequestWithConfig(req2, resp2, config)
+
+	assert.Empty(t, resp2.Header().Get("Access-Control-Allow-Private-Network"))
+
+	// TEST 3: Private network requested but not allowed
+	config.PrivateNetworkAllowed = false
+	req3 := createDummyRequest()
+	req3.Request.Header.Set("Access-Control-Request-Method", "GET")
+	req3.Request.Header.Set("Access-Control-Request-Private-Network", "true")
+	resp3 := createDummyResponse()
+	c.doPreflightRequestWithConfig(req3, resp3, config)
+
+	assert.Empty(t, resp3.Header().Get("Access-Control-Allow-Private-Network"))
+	assert.Equal(t, "GET", resp3.Header().Get("Access-Control-Allow-Methods"))
+}
+
 func TestSetOptionHeaders(t *testing.T) {
 	c := CrossOriginResourceSharing{}
 	config := &MergedCORSConfig{
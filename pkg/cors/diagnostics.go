@@ -0,0 +1,140 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DebugHeader is the response header describing the CORS decision, set for Diagnostics.DebugOrigins
+	DebugHeader = "X-CORS-Debug"
+
+	// DecisionAttribute is the key for the Decision stored in the request when diagnostics are enabled
+	DecisionAttribute = "CORSDecision"
+)
+
+// RejectionReason tells why the filter didn't add the CORS headers to a response.
+type RejectionReason string
+
+const (
+	RejectionNone             RejectionReason = ""
+	RejectionOriginNotAllowed RejectionReason = "origin_not_allowed"
+	RejectionMethodNotAllowed RejectionReason = "method_not_allowed"
+	RejectionHeaderNotAllowed RejectionReason = "header_not_allowed"
+)
+
+// ConfigSource tells which config a CORS decision was made with.
+type ConfigSource string
+
+const (
+	// ConfigSourceStatic is the service config, used when there is no namespace config
+	ConfigSourceStatic ConfigSource = "static"
+	// ConfigSourceNamespace is the service config merged with the namespace config
+	ConfigSourceNamespace ConfigSource = "namespace"
+	// ConfigSourceFallback is the service config, used because the namespace config couldn't be fetched
+	ConfigSourceFallback ConfigSource = "fallback"
+)
+
+// Decision is the outcome of the CORS filter for a cross-origin request.
+type Decision struct {
+	Origin    string
+	Preflight bool
+	Namespace string // empty when no namespace was resolved
+	Source    ConfigSource
+	Reason    RejectionReason // RejectionNone when the CORS headers were added
+	Detail    string          // rejected method or header
+}
+
+// Allowed reports whether the CORS headers were added to the response.
+func (d Decision) Allowed() bool {
+	return d.Reason == RejectionNone
+}
+
+// String formats the decision as the DebugHeader value.
+func (d Decision) String() string {
+	s := fmt.Sprintf("allowed=%t; source=%s", d.Allowed(), d.Source)
+	if d.Namespace != "" {
+		s += "; namespace=" + d.Namespace
+	}
+	if !d.Allowed() {
+		s += "; reason=" + string(d.Reason)
+	}
+	if d.Detail != "" {
+		s += "; detail=" + d.Detail
+	}
+	return s
+}
+
+// DecisionObserver is notified of every CORS decision, e.g. to count rejections in a metric labeled by reason.
+type DecisionObserver func(decision Decision)
+
+// Diagnostics configures the CORS decision diagnostics. They are disabled by default.
+type Diagnostics struct {
+	// Enabled logs every rejection with its reason, namespace and config source at info level,
+	// and stores the Decision in the request under DecisionAttribute.
+	Enabled bool
+
+	// DebugOrigins are the origin patterns receiving the DebugHeader, e.g. the origins of internal tools.
+	// The patterns use the AllowedDomains syntax. Empty means no origin.
+	DebugOrigins []string
+
+	// Observer is called for every decision, whether Enabled is set or not.
+	Observer DecisionObserver
+}
+
+// RetrieveDecision returns the CORS decision of the request, or nil when diagnostics are disabled
+// or the request has no Origin header.
+func RetrieveDecision(request *restful.Request) *Decision {
+	decision, _ := request.Attribute(DecisionAttribute).(*Decision)
+	return decision
+}
+
+// reportDecision applies the diagnostics to decision. It must be called before the response is written.
+func (c *CrossOriginResourceSharing) reportDecision(req *restful.Request, resp *restful.Response, decision Decision) {
+	if c.Diagnostics.Observer != nil {
+		c.Diagnostics.Observer(decision)
+	}
+	if c.Metrics != nil {
+		c.Metrics.OriginDecision(decision.Namespace, decision.Allowed())
+	}
+
+	if !decision.Allowed() {
+		logrus.Debugf("HTTP Origin:%s rejected: %s", decision.Origin, decision)
+	}
+
+	if !c.Diagnostics.Enabled {
+		return
+	}
+
+	req.SetAttribute(DecisionAttribute, &decision)
+	if !decision.Allowed() {
+		logrus.WithFields(logrus.Fields{
+			"origin":    decision.Origin,
+			"preflight": decision.Preflight,
+			"namespace": decision.Namespace,
+			"source":    decision.Source,
+			"reason":    decision.Reason,
+			"detail":    decision.Detail,
+		}).Info("cors: request rejected")
+	}
+
+	if len(c.Diagnostics.DebugOrigins) > 0 && c.debugMatcher.MatchOrigin(decision.Origin) {
+		resp.Header().Set(DebugHeader, decision.String())
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+func runDiagnosticsFilter(filter *CrossOriginResourceSharing, method, origin, namespace string, headers map[string]string) (*restful.Request, *httptest.ResponseRecorder) {
+	httpReq := httptest.NewRequest(method, "/", nil)
+	httpReq.Header.Set(restful.HEADER_Origin, origin)
+	if namespace != "" {
+		httpReq.Header.Set(namespaceHeader, namespace)
+	}
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	req := restful.NewRequest(httpReq)
+	recorder := httptest.NewRecorder()
+	called := false
+	filter.Filter(req, restful.NewResponse(recorder), createTestFilterChain(&called))
+	return req, recorder
+}
+
+func TestDiagnosticsDecisions(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://game1.io"}}
+	mockClient.errors["broken"] = errors.New("config service unavailable")
+
+	var observed []Decision
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+		ConfigClient:   mockClient,
+		Diagnostics: Diagnostics{
+			Enabled:  true,
+			Observer: func(decision Decision) { observed = append(observed, decision) },
+		},
+	}
+
+	preflight := func(method, headers string) map[string]string {
+		h := map[string]string{restful.HEADER_AccessControlRequestMethod: method}
+		if headers != "" {
+			h[restful.HEADER_AccessControlRequestHeaders] = headers
+		}
+		return h
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		origin    string
+		namespace string
+		headers   map[string]string
+		expected  Decision
+	}{
+		{"static allowed", "GET", "https://service.com", "", nil,
+			Decision{Source: ConfigSourceStatic}},
+		{"namespace allowed", "GET", "https://game1.io", "game1", nil,
+			Decision{Namespace: "game1", Source: ConfigSourceNamespace}},
+		{"origin not allowed", "GET", "https://evil.com", "game1", nil,
+			Decision{Namespace: "game1", Source: ConfigSourceNamespace, Reason: RejectionOriginNotAllowed}},
+		{"fallback", "GET", "https://game1.io", "broken", nil,
+			Decision{Namespace: "broken", Source: ConfigSourceFallback, Reason: RejectionOriginNotAllowed}},
+		{"method not allowed", "OPTIONS", "https://service.com", "", preflight("DELETE", ""),
+			Decision{Preflight: true, Source: ConfigSourceStatic, Reason: RejectionMethodNotAllowed, Detail: "DELETE"}},
+		{"header not allowed", "OPTIONS", "https://service.com", "", preflight("POST", "Content-Type, X-Secret"),
+			Decision{Preflight: true, Source: ConfigSourceStatic, Reason: RejectionHeaderNotAllowed, Detail: "X-Secret"}},
+		{"preflight allowed", "OPTIONS", "https://service.com", "", preflight("POST", "Content-Type"),
+			Decision{Preflight: true, Source: ConfigSourceStatic}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observed = nil
+			req, _ := runDiagnosticsFilter(filter, tt.method, tt.origin, tt.namespace, tt.headers)
+
+			tt.expected.Origin = tt.origin
+			if len(observed) != 1 || observed[0] != tt.expected {
+				t.Errorf("Expected decision %+v, observed %+v", tt.expected, observed)
+			}
+			if decision := RetrieveDecision(req); decision == nil || *decision != tt.expected {
+				t.Errorf("Expected request decision %+v, got %+v", tt.expected, decision)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsDebugHeader(t *testing.T) {
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com", "https://tools.internal.io"},
+		AllowedMethods: []string{"GET"},
+		Diagnostics: Diagnostics{
+			Enabled:      true,
+			DebugOrigins: []string{"https://tools.internal.io", "https://*.debug.io"},
+		},
+	}
+	preflight := map[string]string{restful.HEADER_AccessControlRequestMethod: "DELETE"}
+
+	_, recorder := runDiagnosticsFilter(filter, "OPTIONS", "https://tools.internal.io", "", preflight)
+	expected := "allowed=false; source=static; reason=method_not_allowed; detail=DELETE"
+	if got := recorder.Header().Get(DebugHeader); got != expected {
+		t.Errorf("Expected %s %q, got %q", DebugHeader, expected, got)
+	}
+
+	_, recorder = runDiagnosticsFilter(filter, "GET", "https://a.debug.io", "", nil)
+	expected = "allowed=false; source=static; reason=origin_not_allowed"
+	if got := recorder.Header().Get(DebugHeader); got != expected {
+		t.Errorf("Expected %s %q, got %q", DebugHeader, expected, got)
+	}
+
+	_, recorder = runDiagnosticsFilter(filter, "GET", "https://service.com", "", nil)
+	if got := recorder.Header().Get(DebugHeader); got != "" {
+		t.Errorf("Expected no %s for an origin not in DebugOrigins, got %q", DebugHeader, got)
+	}
+}
+
+func TestDiagnosticsDisabled(t *testing.T) {
+	var observed int
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		Diagnostics: Diagnostics{
+			DebugOrigins: []string{"https://evil.com"},
+			Observer:     func(Decision) { observed++ },
+		},
+	}
+
+	req, recorder := runDiagnosticsFilter(filter, "GET", "https://evil.com", "", nil)
+	if recorder.Header().Get(DebugHeader) != "" {
+		t.Error("Expected no debug header when diagnostics are disabled")
+	}
+	if RetrieveDecision(req) != nil {
+		t.Error("Expected no request decision when diagnostics are disabled")
+	}
+	if observed != 1 {
+		t.Errorf("Expected the observer to be called when diagnostics are disabled, got %d calls", observed)
+	}
+}
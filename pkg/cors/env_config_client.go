@@ -0,0 +1,117 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Default environment variable prefixes of an EnvConfigClient.
+const (
+	DefaultEnvConfigPrefix          = "CORS_CONFIG_"
+	DefaultEnvSubdomainConfigPrefix = "CORS_SUBDOMAIN_CONFIG_"
+)
+
+// EnvConfigClient is a ConfigClient reading the namespace configs from environment variables holding
+// the JSON CORSConfigValue, e.g. CORS_CONFIG_GAME1='{"allowed_domains":["https://*.game1.io"]}'.
+// The variable name is the prefix followed by the namespace in upper case, with every character other
+// than a letter or a digit replaced by an underscore.
+// The environment is read once by NewEnvConfigClient.
+type EnvConfigClient struct {
+	namespaces map[string]*CORSConfigValue
+	subdomains map[string]*CORSSubdomainConfig
+}
+
+// NewEnvConfigClient reads the configs from the variables starting with DefaultEnvConfigPrefix and
+// DefaultEnvSubdomainConfigPrefix. The namespace configs are checked by validator
+// (NewConfigValidatorFromEnv when nil).
+func NewEnvConfigClient(validator *ConfigValidator) (*EnvConfigClient, error) {
+	return NewEnvConfigClientWithPrefix(DefaultEnvConfigPrefix, DefaultEnvSubdomainConfigPrefix, validator)
+}
+
+// NewEnvConfigClientWithPrefix is NewEnvConfigClient with custom variable prefixes.
+func NewEnvConfigClientWithPrefix(configPrefix, subdomainPrefix string, validator *ConfigValidator) (*EnvConfigClient, error) {
+	if validator == nil {
+		validator = NewConfigValidatorFromEnv()
+	}
+
+	c := &EnvConfigClient{
+		namespaces: make(map[string]*CORSConfigValue),
+		subdomains: make(map[string]*CORSSubdomainConfig),
+	}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		switch {
+		// the subdomain prefix is checked first, it may start with the config prefix
+		case strings.HasPrefix(name, subdomainPrefix):
+			var config CORSSubdomainConfig
+			if err := json.Unmarshal([]byte(value), &config); err != nil {
+				return nil, fmt.Errorf("cors: unable to parse %s: %w", name, err)
+			}
+			c.subdomains[strings.TrimPrefix(name, subdomainPrefix)] = &config
+		case strings.HasPrefix(name, configPrefix):
+			var config CORSConfigValue
+			if err := json.Unmarshal([]byte(value), &config); err != nil {
+				return nil, fmt.Errorf("cors: unable to parse %s: %w", name, err)
+			}
+			key := strings.TrimPrefix(name, configPrefix)
+			validated, err := validator.Validate(key, &config)
+			if err != nil {
+				return nil, fmt.Errorf("cors: %s: %w", name, err)
+			}
+			c.namespaces[key] = validated
+		}
+	}
+
+	return c, nil
+}
+
+// envKey is the variable name suffix of namespace.
+func envKey(namespace string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, namespace)
+}
+
+// GetCORSConfig returns the config of namespace, or (nil, nil) when no variable is set for it.
+func (c *EnvConfigClient) GetCORSConfig(namespace string) (*CORSConfigValue, error) {
+	return c.namespaces[envKey(namespace)], nil
+}
+
+// GetCORSConfigWithContext is GetCORSConfig.
+func (c *EnvConfigClient) GetCORSConfigWithContext(_ context.Context, namespace string) (*CORSConfigValue, error) {
+	return c.GetCORSConfig(namespace)
+}
+
+// GetSubdomainConfig returns the subdomain settings of publisherNamespace, or (nil, nil) when no variable is set for it.
+func (c *EnvConfigClient) GetSubdomainConfig(publisherNamespace string) (*CORSSubdomainConfig, error) {
+	return c.subdomains[envKey(publisherNamespace)], nil
+}
+
+// GetSubdomainConfigWithContext is GetSubdomainConfig.
+func (c *EnvConfigClient) GetSubdomainConfigWithContext(_ context.Context, publisherNamespace string) (*CORSSubdomainConfig, error) {
+	return c.GetSubdomainConfig(publisherNamespace)
+}
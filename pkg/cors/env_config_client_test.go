@@ -0,0 +1,45 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"testing"
+)
+
+func TestEnvConfigClient(t *testing.T) {
+	t.Setenv("CORS_CONFIG_GAME_1", `{"allowed_domains": ["https://game1.io"], "max_age": 30}`)
+	t.Setenv("CORS_SUBDOMAIN_CONFIG_ACCELBYTE", `{"subdomain_enabled": true, "subdomain_base_domain": "example.com"}`)
+
+	client, err := NewEnvConfigClient(&ConfigValidator{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	config, err := client.GetCORSConfig("game-1")
+	if err != nil || config == nil || !containsDomain(config.AllowedDomains, "https://game1.io") || config.MaxAge != 30 {
+		t.Errorf("Unexpected game-1 config: %+v, %v", config, err)
+	}
+	if config, _ := client.GetCORSConfig("game2"); config != nil {
+		t.Errorf("Expected no config for game2, got %+v", config)
+	}
+	if subdomain, _ := client.GetSubdomainConfig("accelbyte"); subdomain == nil || subdomain.SubdomainBaseDomain != "example.com" {
+		t.Errorf("Unexpected subdomain config: %+v", subdomain)
+	}
+
+	t.Setenv("CORS_CONFIG_BROKEN", `{`)
+	if _, err := NewEnvConfigClient(&ConfigValidator{}); err == nil {
+		t.Error("Expected an error for a malformed variable")
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFileReloadInterval is how often a FileConfigClient checks its file for changes by default.
+const defaultFileReloadInterval = 5 * time.Second
+
+// errEmptyConfigFile rejects an empty or null config file, "{}" removes every config.
+var errEmptyConfigFile = errors.New("empty document")
+
+// FileConfig is the content of a FileConfigClient file, in JSON or YAML with the JSON field names:
+//
+//	namespaces:
+//	  game1:
+//	    allowed_domains: ["https://*.game1.io"]
+//	    cookies_allowed: true
+//	subdomains:
+//	  accelbyte:
+//	    subdomain_enabled: true
+//	    subdomain_base_domain: example.com
+type FileConfig struct {
+	Namespaces map[string]*CORSConfigValue     `json:"namespaces"`
+	Subdomains map[string]*CORSSubdomainConfig `json:"subdomains"` // keyed by publisher namespace
+}
+
+// FileConfigClientOptions configures a FileConfigClient.
+type FileConfigClientOptions struct {
+	// ReloadInterval is how often the file modification time is checked (default 5s), a negative value
+	// disables the hot reload.
+	ReloadInterval time.Duration
+
+	// Validator checks the namespace configs of the file (default NewConfigValidatorFromEnv).
+	// In ValidationReject mode a file with an unsafe config is not loaded.
+	Validator *ConfigValidator
+}
+
+// FileConfigClient is a ConfigClient reading the namespace configs from a local JSON or YAML file,
+// e.g. for local development, on-prem deployments and tests. Files with a .yaml or .yml extension are
+// parsed as YAML, other files as JSON.
+// The file is reloaded when its modification time or size changes. A file that can't be loaded, an empty
+// one included, is logged and the previously loaded configs are kept. Replace the file atomically anyway,
+// e.g. by renaming a temporary file, since a partially written YAML file may still parse.
+// The filter using the client validates the configs as inheriting its CookiesAllowed, like the configs of
+// the client it creates from ConfigServiceURL, see ConfigValidator.InheritedCookiesAllowed.
+type FileConfigClient struct {
+	path    string
+	options FileConfigClientOptions
+
+	reloadMu sync.Mutex // serializes the reloads, so the last one uses the current validator
+
+	mu     sync.RWMutex
+	config *FileConfig
+
+	// modification time and size of the file when it was last read, even if it couldn't be loaded
+	modTime time.Time
+	size    int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFileConfigClient loads path and, unless disabled, starts watching it for changes.
+// Call Close to stop watching.
+func NewFileConfigClient(path string, options FileConfigClientOptions) (*FileConfigClient, error) {
+	if options.ReloadInterval == 0 {
+		options.ReloadInterval = defaultFileReloadInterval
+	}
+	if options.Validator == nil {
+		options.Validator = NewConfigValidatorFromEnv()
+	}
+
+	c := &FileConfigClient{
+		path:    path,
+		options: options,
+		stop:    make(chan struct{}),
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+
+	if options.ReloadInterval > 0 {
+		go c.watch()
+	}
+
+	return c, nil
+}
+
+// GetCORSConfig returns the config of namespace, or (nil, nil) when the file has none.
+// The same pointer is returned until the file is reloaded.
+func (c *FileConfigClient) GetCORSConfig(namespace string) (*CORSConfigValue, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.Namespaces[namespace], nil
+}
+
+// GetCORSConfigWithContext is GetCORSConfig, the file is never read on the request path.
+func (c *FileConfigClient) GetCORSConfigWithContext(_ context.Context, namespace string) (*CORSConfigValue, error) {
+	return c.GetCORSConfig(namespace)
+}
+
+// GetSubdomainConfig returns the subdomain settings of publisherNamespace, or (nil, nil) when the file has none.
+func (c *FileConfigClient) GetSubdomainConfig(publisherNamespace string) (*CORSSubdomainConfig, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.Subdomains[publisherNamespace], nil
+}
+
+// GetSubdomainConfigWithContext is GetSubdomainConfig, the file is never read on the request path.
+func (c *FileConfigClient) GetSubdomainConfigWithContext(_ context.Context, publisherNamespace string) (*CORSSubdomainConfig, error) {
+	return c.GetSubdomainConfig(publisherNamespace)
+}
+
+// InvalidateCORSConfig reloads the file, the configs of a file are always loaded together.
+func (c *FileConfigClient) InvalidateCORSConfig(string) {
+	c.reloadAndLog()
+}
+
+// InvalidateAllCORSConfigs reloads the file.
+func (c *FileConfigClient) InvalidateAllCORSConfigs() {
+	c.reloadAndLog()
+}
+
+// Reload reads the file now. On error the previously loaded configs are kept.
+func (c *FileConfigClient) Reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return fmt.Errorf("cors: unable to read config file: %w", err)
+	}
+
+	config, err := c.load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modTime = info.ModTime()
+	c.size = info.Size()
+	if err != nil {
+		return err
+	}
+	c.config = config
+
+	return nil
+}
+
+// setInheritedCookiesAllowed implements inheritedCookiesAllowedSetter, the file is reloaded when the validation changes.
+func (c *FileConfigClient) setInheritedCookiesAllowed(cookiesAllowed bool) {
+	c.mu.Lock()
+	if c.options.Validator.InheritedCookiesAllowed == cookiesAllowed {
+		c.mu.Unlock()
+		return
+	}
+	validator := *c.options.Validator
+	validator.InheritedCookiesAllowed = cookiesAllowed
+	c.options.Validator = &validator
+	c.mu.Unlock()
+
+	c.reloadAndLog()
+}
+
+// Close stops watching the file. The loaded configs are still served.
+func (c *FileConfigClient) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *FileConfigClient) watch() {
+	ticker := time.NewTicker(c.options.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if c.changed() {
+				c.reloadAndLog()
+			}
+		}
+	}
+}
+
+// changed reports whether the file modification time or size differs from the last read file.
+func (c *FileConfigClient) changed() bool {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		logrus.Errorf("cors: unable to check config file %s: %v", c.path, err)
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !info.ModTime().Equal(c.modTime) || info.Size() != c.size
+}
+
+func (c *FileConfigClient) reloadAndLog() {
+	if err := c.Reload(); err != nil {
+		logrus.Errorf("cors: keeping the previous configs of %s: %v", c.path, err)
+		return
+	}
+	logrus.Infof("cors: reloaded config file %s", c.path)
+}
+
+// load parses and validates the file.
+func (c *FileConfigClient) load() (*FileConfig, error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("cors: unable to read config file: %w", err)
+	}
+
+	config, err := parseFileConfig(c.path, data)
+	if err != nil {
+		return nil, fmt.Errorf("cors: unable to parse config file %s: %w", c.path, err)
+	}
+
+	c.mu.RLock()
+	validator := c.options.Validator
+	c.mu.RUnlock()
+	for namespace, value := range config.Namespaces {
+		validated, err := validator.Validate(namespace, value)
+		if err != nil {
+			return nil, fmt.Errorf("cors: config file %s: %w", c.path, err)
+		}
+		config.Namespaces[namespace] = validated
+	}
+
+	return config, nil
+}
+
+// parseFileConfig decodes data as YAML or JSON depending on the extension of path.
+// YAML is converted to JSON first, so both formats use the JSON field names. An empty document is an error.
+func parseFileConfig(path string, data []byte) (*FileConfig, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	// an empty document is more likely a file being written than a deliberate removal of every config
+	if trimmed := strings.TrimSpace(string(data)); trimmed == "" || trimmed == "null" {
+		return nil, errEmptyConfigFile
+	}
+
+	config := &FileConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// replaceConfigFile writes path atomically, so the watcher never reads it truncated.
+func replaceConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	writeConfigFile(t, tmp, content)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("Failed to replace %s: %v", path, err)
+	}
+}
+
+func TestFileConfigClient_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.json")
+	writeConfigFile(t, path, `{
+		"namespaces": {"game1": {"allowed_domains": ["https://*.game1.io"], "cookies_allowed": true, "max_age": 60}},
+		"subdomains": {"accelbyte": {"subdomain_enabled": true, "subdomain_base_domain": "example.com"}}
+	}`)
+
+	client, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: -1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	config, err := client.GetCORSConfig("game1")
+	if err != nil || config == nil {
+		t.Fatalf("Expected game1 config, got %v, %v", config, err)
+	}
+	if !containsDomain(config.AllowedDomains, "https://*.game1.io") || config.CookiesAllowed == nil || !*config.CookiesAllowed || config.MaxAge != 60 {
+		t.Errorf("Unexpected game1 config: %+v", config)
+	}
+	if config, err := client.GetCORSConfig("unknown"); config != nil || err != nil {
+		t.Errorf("Expected (nil, nil) for an unknown namespace, got %v, %v", config, err)
+	}
+
+	subdomain, err := client.GetSubdomainConfig("accelbyte")
+	if err != nil || subdomain == nil || !subdomain.SubdomainEnabled || subdomain.SubdomainBaseDomain != "example.com" {
+		t.Errorf("Unexpected subdomain config: %+v, %v", subdomain, err)
+	}
+}
+
+func TestFileConfigClient_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.yaml")
+	writeConfigFile(t, path, `
+namespaces:
+  game1:
+    allowed_domains:
+      - https://*.game1.io
+    allowed_methods: [GET, POST]
+    private_network_allowed: true
+`)
+
+	client, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: -1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	config, _ := client.GetCORSConfig("game1")
+	if config == nil || !containsDomain(config.AllowedDomains, "https://*.game1.io") ||
+		len(config.AllowedMethods) != 2 || config.PrivateNetworkAllowed == nil || !*config.PrivateNetworkAllowed {
+		t.Errorf("Unexpected game1 config: %+v", config)
+	}
+}
+
+func TestFileConfigClient_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NewFileConfigClient(filepath.Join(dir, "missing.json"), FileConfigClientOptions{}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	empty := filepath.Join(dir, "empty.yaml")
+	writeConfigFile(t, empty, "# no config yet\n")
+	if _, err := NewFileConfigClient(empty, FileConfigClientOptions{}); !errors.Is(err, errEmptyConfigFile) {
+		t.Errorf("Expected an error for an empty file, got %v", err)
+	}
+
+	path := filepath.Join(dir, "cors.json")
+	writeConfigFile(t, path, `{"namespaces": `)
+	if _, err := NewFileConfigClient(path, FileConfigClientOptions{}); err == nil {
+		t.Error("Expected an error for a malformed file")
+	}
+
+	writeConfigFile(t, path, `{"namespaces": {"game1": {"allowed_domains": ["*"], "cookies_allowed": true}}}`)
+	_, err := NewFileConfigClient(path, FileConfigClientOptions{Validator: &ConfigValidator{Mode: ValidationReject}})
+	var validationErr *ConfigValidationError
+	if !errors.As(err, &validationErr) || validationErr.Namespace != "game1" {
+		t.Errorf("Expected a validation error for game1, got %v", err)
+	}
+}
+
+func TestFileConfigClient_Sanitize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.json")
+	writeConfigFile(t, path, `{"namespaces": {"game1": {"allowed_domains": ["https://*.io", "https://game1.io"]}}}`)
+
+	client, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: -1, Validator: &ConfigValidator{}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	config, _ := client.GetCORSConfig("game1")
+	if containsDomain(config.AllowedDomains, "https://*.io") || !containsDomain(config.AllowedDomains, "https://game1.io") {
+		t.Errorf("Expected the too broad wildcard to be removed, got %v", config.AllowedDomains)
+	}
+}
+
+func TestFileConfigClient_InheritsFilterCookiesAllowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.json")
+	writeConfigFile(t, path, `{"namespaces": {"game1": {"allowed_domains": ["*", "https://game1.io"]}}}`)
+
+	client, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: -1, Validator: &ConfigValidator{}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config, _ := client.GetCORSConfig("game1"); !containsDomain(config.AllowedDomains, "*") {
+		t.Fatalf("Expected the wildcard to be kept without cookies, got %v", config.AllowedDomains)
+	}
+
+	filter := &CrossOriginResourceSharing{
+		CookiesAllowed: true,
+		ConfigClient:   NewChainedConfigClient(client),
+	}
+	filter.Init()
+	if domains := allowedDomainsOf(filter, "game1"); containsDomain(domains, "*") || !containsDomain(domains, "https://game1.io") {
+		t.Errorf("Expected the wildcard to be removed from a config inheriting cookies_allowed, got %v", domains)
+	}
+}
+
+func TestFileConfigClient_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.json")
+	writeConfigFile(t, path, `{"namespaces": {"game1": {"allowed_domains": ["https://game1.io"]}}}`)
+
+	client, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	first, _ := client.GetCORSConfig("game1")
+	if again, _ := client.GetCORSConfig("game1"); again != first {
+		t.Error("Expected the same config pointer until the file is reloaded")
+	}
+
+	replaceConfigFile(t, path, `{"namespaces": {"game1": {"allowed_domains": ["https://game1.io", "https://game1.net"]}}}`)
+	waitForConfig(t, func() bool {
+		config, _ := client.GetCORSConfig("game1")
+		return containsDomain(config.AllowedDomains, "https://game1.net")
+	})
+
+	// a broken file keeps the previous configs
+	replaceConfigFile(t, path, `{"namespaces": {`)
+	time.Sleep(30 * time.Millisecond)
+	config, _ := client.GetCORSConfig("game1")
+	if config == nil || !containsDomain(config.AllowedDomains, "https://game1.net") {
+		t.Errorf("Expected the previous config to be kept, got %+v", config)
+	}
+
+	// so does an empty one
+	for _, content := range []string{"", "null", " \n"} {
+		replaceConfigFile(t, path, content)
+		if err := client.Reload(); !errors.Is(err, errEmptyConfigFile) {
+			t.Errorf("Expected an empty file error for %q, got %v", content, err)
+		}
+		if config, _ := client.GetCORSConfig("game1"); config == nil {
+			t.Errorf("Expected the previous config to be kept after the empty file %q", content)
+		}
+	}
+}
+
+func waitForConfig(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the config file to be reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewCrossOriginResourceSharingWithConfigClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cors.yml")
+	writeConfigFile(t, path, "namespaces:\n  game1:\n    allowed_domains: [\"https://game1.io\"]\n")
+
+	fileClient, err := NewFileConfigClient(path, FileConfigClientOptions{ReloadInterval: -1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	filter := NewCrossOriginResourceSharingWithConfigClient(
+		NewChainedConfigClient(fileClient), "", []string{"https://service.io"}, nil, nil, nil, false, 0)
+
+	for origin, allowed := range map[string]bool{
+		"https://game1.io":   true,
+		"https://service.io": true,
+		"https://other.io":   false,
+	} {
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Header.Set(restful.HEADER_Origin, origin)
+		httpReq.Header.Set(namespaceHeader, "game1")
+		recorder := httptest.NewRecorder()
+
+		called := false
+		filter.Filter(restful.NewRequest(httpReq), restful.NewResponse(recorder), createTestFilterChain(&called))
+
+		if got := recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin) == origin; got != allowed {
+			t.Errorf("Origin %s: expected allowed=%v", origin, allowed)
+		}
+	}
+}
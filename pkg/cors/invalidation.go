@@ -0,0 +1,108 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CORSConfigKey is the config service key of the namespace CORS config
+	CORSConfigKey = "CORS"
+	// CORSSubdomainConfigKey is the config service key of the publisher namespace subdomain settings
+	CORSSubdomainConfigKey = "CORS_SUBDOMAIN"
+)
+
+// ConfigChangeEvent tells that a config of the config service changed.
+type ConfigChangeEvent struct {
+	Namespace string // changed namespace, empty for all namespaces
+	Key       string // changed config key, empty for any key
+}
+
+// ConfigChangeSubscriber is implemented by message-bus listeners delivering config changes.
+// Subscribe must call handler for every change until unsubscribe is called.
+type ConfigChangeSubscriber interface {
+	Subscribe(handler func(event ConfigChangeEvent)) (unsubscribe func(), err error)
+}
+
+// Subscribe evicts cached configs on every change delivered by subscriber.
+func (c *CrossOriginResourceSharing) Subscribe(subscriber ConfigChangeSubscriber) (unsubscribe func(), err error) {
+	return subscriber.Subscribe(c.HandleConfigChange)
+}
+
+// HandleConfigChange evicts the cached configs affected by event. Changes of other config keys are ignored.
+func (c *CrossOriginResourceSharing) HandleConfigChange(event ConfigChangeEvent) {
+	switch event.Key {
+	case "":
+		if event.Namespace == "" {
+			c.InvalidateAll()
+		} else {
+			c.InvalidateNamespace(event.Namespace)
+		}
+	case CORSConfigKey:
+		if event.Namespace == "" {
+			c.invalidateCORSConfigs()
+		} else {
+			c.InvalidateNamespace(event.Namespace)
+		}
+	case CORSSubdomainConfigKey:
+		if event.Namespace == "" || event.Namespace == c.PublisherNamespace {
+			c.invalidateSubdomainConfig()
+		}
+	}
+}
+
+// InvalidateNamespace evicts the cached config of namespace, so the next request fetches it again.
+// The namespaces inheriting the config of namespace, e.g. the games of a studio, are evicted too.
+// Every namespace inherits the publisher config, so invalidating the PublisherNamespace evicts every namespace.
+func (c *CrossOriginResourceSharing) InvalidateNamespace(namespace string) {
+	if namespace == c.PublisherNamespace {
+		c.InvalidateAll()
+		return
+	}
+
+	c.Init()
+	if invalidator, ok := c.ConfigClient.(ConfigInvalidator); ok {
+		invalidator.InvalidateCORSConfig(namespace)
+	}
+	mergedConfigs := c.mergedConfigCache()
+	for key, value := range mergedConfigs.GetALL(false) {
+		if key == namespace || value.(*mergedConfigEntry).source.SourceNamespace == namespace {
+			mergedConfigs.Remove(key)
+		}
+	}
+	logrus.Debugf("cors: invalidated CORS config of namespace %s", namespace)
+}
+
+// InvalidateAll evicts every cached namespace config and the subdomain settings.
+func (c *CrossOriginResourceSharing) InvalidateAll() {
+	c.invalidateCORSConfigs()
+	c.invalidateSubdomainConfig()
+}
+
+func (c *CrossOriginResourceSharing) invalidateCORSConfigs() {
+	c.Init()
+	if invalidator, ok := c.ConfigClient.(ConfigInvalidator); ok {
+		invalidator.InvalidateAllCORSConfigs()
+	}
+	c.mergedConfigCache().Purge()
+	logrus.Debugf("cors: invalidated all CORS configs")
+}
+
+func (c *CrossOriginResourceSharing) invalidateSubdomainConfig() {
+	c.subdomainMu.Lock()
+	c.subdomainLoaded = false
+	c.subdomainMu.Unlock()
+}
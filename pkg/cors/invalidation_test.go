@@ -0,0 +1,332 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// configServer is a config service returning the CORS config currently stored for each namespace,
+// or the config of its parent namespace when it has none.
+type configServer struct {
+	mu            sync.Mutex
+	domains       map[string]string
+	parents       map[string]string
+	subdomainHits int32
+}
+
+func newConfigServer() (*configServer, *httptest.Server) {
+	cs := &configServer{domains: make(map[string]string), parents: make(map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/configs/CORS_SUBDOMAIN") {
+			atomic.AddInt32(&cs.subdomainHits, 1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		namespace := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/admin/namespaces/"), "/")[0]
+		cs.mu.Lock()
+		source := namespace
+		domain, ok := cs.domains[namespace]
+		if parent, inherits := cs.parents[namespace]; !ok && inherits {
+			source = parent
+			domain, ok = cs.domains[parent]
+		}
+		cs.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ConfigServiceResponse{
+			Namespace: source,
+			Key:       CORSConfigKey,
+			Value:     fmt.Sprintf(`{"allowed_domains": [%q]}`, domain),
+		})
+	}))
+	return cs, server
+}
+
+func (cs *configServer) setDomain(namespace, domain string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.domains[namespace] = domain
+}
+
+func (cs *configServer) setParent(namespace, parent string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.parents[namespace] = parent
+}
+
+func newInvalidationTestFilter(serverURL string) *CrossOriginResourceSharing {
+	return &CrossOriginResourceSharing{
+		AllowedDomains:     []string{"https://service.com"},
+		ConfigClient:       NewConfigClientWithIAM(serverURL, time.Hour, nil, TransportConfig{}),
+		PublisherNamespace: "publisher",
+	}
+}
+
+func allowedDomainsOf(filter *CrossOriginResourceSharing, namespace string) []string {
+	httpReq := httptest.NewRequest("GET", "/", nil)
+	httpReq.Header.Set(namespaceHeader, namespace)
+	config := filter.getConfigWithDynamicResolution(restful.NewRequest(httpReq))
+	if config == nil {
+		return nil
+	}
+	return config.AllowedDomains
+}
+
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInvalidateNamespace(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	filter := newInvalidationTestFilter(server.URL)
+
+	cs.setDomain("game1", "https://v1.game1.io")
+	cs.setDomain("game2", "https://v1.game2.io")
+	allowedDomainsOf(filter, "game1")
+	allowedDomainsOf(filter, "game2")
+
+	cs.setDomain("game1", "https://v2.game1.io")
+	cs.setDomain("game2", "https://v2.game2.io")
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v1.game1.io") {
+		t.Fatal("Expected cached config before invalidation")
+	}
+
+	filter.InvalidateNamespace("game1")
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.game1.io") {
+		t.Error("Expected refreshed config after namespace invalidation")
+	}
+	if !containsDomain(allowedDomainsOf(filter, "game2"), "https://v1.game2.io") {
+		t.Error("Expected other namespaces to stay cached")
+	}
+}
+
+func TestInvalidateNamespaceEvictsInheritingNamespaces(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	filter := newInvalidationTestFilter(server.URL)
+
+	cs.setParent("game1", "studio1")
+	cs.setParent("game3", "studio2")
+	cs.setDomain("studio1", "https://v1.studio1.io")
+	cs.setDomain("game2", "https://v1.game2.io")
+	allowedDomainsOf(filter, "game1")
+	allowedDomainsOf(filter, "game2")
+	if domains := allowedDomainsOf(filter, "game3"); containsDomain(domains, "https://v1.studio2.io") {
+		t.Fatalf("Expected game3 without config, got %v", domains)
+	}
+
+	cs.setDomain("studio1", "https://v2.studio1.io")
+	cs.setDomain("game2", "https://v2.game2.io")
+	filter.InvalidateNamespace("studio1")
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.studio1.io") {
+		t.Error("Expected the studio invalidation to evict the config game1 inherits")
+	}
+	if !containsDomain(allowedDomainsOf(filter, "game2"), "https://v1.game2.io") {
+		t.Error("Expected the namespaces with their own config to stay cached")
+	}
+
+	// a new studio config applies to its namespaces without config
+	cs.setDomain("studio2", "https://v1.studio2.io")
+	filter.InvalidateNamespace("studio2")
+	if !containsDomain(allowedDomainsOf(filter, "game3"), "https://v1.studio2.io") {
+		t.Error("Expected the studio invalidation to evict the namespaces without config")
+	}
+}
+
+func TestInvalidateAll(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	filter := newInvalidationTestFilter(server.URL)
+
+	cs.setDomain("game1", "https://v1.game1.io")
+	allowedDomainsOf(filter, "game1")
+	if hits := atomic.LoadInt32(&cs.subdomainHits); hits != 1 {
+		t.Fatalf("Expected subdomain config to be fetched once, got %d", hits)
+	}
+
+	cs.setDomain("game1", "https://v2.game1.io")
+	filter.InvalidateAll()
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.game1.io") {
+		t.Error("Expected refreshed config after full invalidation")
+	}
+	if hits := atomic.LoadInt32(&cs.subdomainHits); hits != 2 {
+		t.Errorf("Expected subdomain config to be fetched again, got %d fetches", hits)
+	}
+
+	// publisher config is included in every namespace config
+	cs.setDomain("game1", "https://v3.game1.io")
+	filter.InvalidateNamespace("publisher")
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v3.game1.io") {
+		t.Error("Expected publisher invalidation to evict every namespace")
+	}
+}
+
+// fakeSubscriber delivers the events published by the test.
+type fakeSubscriber struct {
+	handler func(event ConfigChangeEvent)
+}
+
+func (s *fakeSubscriber) Subscribe(handler func(event ConfigChangeEvent)) (func(), error) {
+	s.handler = handler
+	return func() { s.handler = nil }, nil
+}
+
+func (s *fakeSubscriber) publish(event ConfigChangeEvent) {
+	if s.handler != nil {
+		s.handler(event)
+	}
+}
+
+func TestSubscribeToConfigChanges(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	filter := newInvalidationTestFilter(server.URL)
+	subscriber := &fakeSubscriber{}
+
+	unsubscribe, err := filter.Subscribe(subscriber)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cs.setDomain("game1", "https://v1.game1.io")
+	allowedDomainsOf(filter, "game1")
+	cs.setDomain("game1", "https://v2.game1.io")
+
+	// other config keys are ignored
+	subscriber.publish(ConfigChangeEvent{Namespace: "game1", Key: "OTHER"})
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v1.game1.io") {
+		t.Error("Expected changes of other keys to be ignored")
+	}
+
+	subscriber.publish(ConfigChangeEvent{Namespace: "game1", Key: CORSConfigKey})
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.game1.io") {
+		t.Error("Expected CORS config change to evict the namespace")
+	}
+
+	subscriber.publish(ConfigChangeEvent{Namespace: "publisher", Key: CORSSubdomainConfigKey})
+	allowedDomainsOf(filter, "game1")
+	if hits := atomic.LoadInt32(&cs.subdomainHits); hits != 2 {
+		t.Errorf("Expected subdomain config change to trigger a fetch, got %d fetches", hits)
+	}
+
+	unsubscribe()
+	cs.setDomain("game1", "https://v3.game1.io")
+	subscriber.publish(ConfigChangeEvent{})
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.game1.io") {
+		t.Error("Expected no invalidation after unsubscribe")
+	}
+}
+
+func TestAdminWebServiceInvalidation(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	filter := newInvalidationTestFilter(server.URL)
+
+	var authorized bool
+	auth := func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		authorized = true
+		chain.ProcessFilter(req, resp)
+	}
+	adminService, err := filter.AdminWebService("/admin/cors", auth)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	container := restful.NewContainer()
+	container.Add(adminService)
+
+	cs.setDomain("game1", "https://v1.game1.io")
+	allowedDomainsOf(filter, "game1")
+	cs.setDomain("game1", "https://v2.game1.io")
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/cors/cache/namespaces/game1", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", recorder.Code)
+	}
+	if !authorized {
+		t.Error("Expected admin filters to be applied")
+	}
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v2.game1.io") {
+		t.Error("Expected refreshed config after namespace invalidation")
+	}
+
+	cs.setDomain("game1", "https://v3.game1.io")
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/cors/cache", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", recorder.Code)
+	}
+	if !containsDomain(allowedDomainsOf(filter, "game1"), "https://v3.game1.io") {
+		t.Error("Expected refreshed config after full invalidation")
+	}
+}
+
+func TestAdminWebServiceRequiresFilter(t *testing.T) {
+	filter := &CrossOriginResourceSharing{AllowedDomains: []string{"https://service.com"}}
+	if _, err := filter.AdminWebService("/admin/cors"); !errors.Is(err, ErrAdminWithoutFilter) {
+		t.Errorf("Expected ErrAdminWithoutFilter, got %v", err)
+	}
+	if _, err := filter.AdminWebService("/admin/cors", nil); !errors.Is(err, ErrAdminWithoutFilter) {
+		t.Errorf("Expected ErrAdminWithoutFilter for a nil filter, got %v", err)
+	}
+}
+
+func TestCacheInvalidateDuringLoad(t *testing.T) {
+	var loadCount int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		if atomic.AddInt32(&loadCount, 1) == 1 {
+			<-release
+		}
+		return &CORSConfigValue{}, nil
+	}
+	cache := NewConfigCacheWithContext(time.Hour, loader)
+
+	done := make(chan struct{})
+	go func() {
+		cache.Get("test-ns")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cache.Invalidate("test-ns")
+	close(release)
+	<-done
+
+	cache.Get("test-ns")
+	if loadCount != 2 {
+		t.Errorf("Expected the load started before the invalidation not to be cached, got %d loads", loadCount)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderCrossOriginOpenerPolicy   = "Cross-Origin-Opener-Policy"
+	HeaderCrossOriginEmbedderPolicy = "Cross-Origin-Embedder-Policy"
+	HeaderCrossOriginResourcePolicy = "Cross-Origin-Resource-Policy"
+)
+
+// Values of the Cross-Origin-Opener-Policy header.
+const (
+	OpenerPolicyUnsafeNone            = "unsafe-none"
+	OpenerPolicySameOriginAllowPopups = "same-origin-allow-popups"
+	OpenerPolicySameOrigin            = "same-origin"
+	OpenerPolicyNoopenerAllowPopups   = "noopener-allow-popups"
+)
+
+// Values of the Cross-Origin-Embedder-Policy header.
+const (
+	EmbedderPolicyUnsafeNone     = "unsafe-none"
+	EmbedderPolicyRequireCorp    = "require-corp"
+	EmbedderPolicyCredentialless = "credentialless"
+)
+
+// Values of the Cross-Origin-Resource-Policy header.
+const (
+	ResourcePolicySameSite    = "same-site"
+	ResourcePolicySameOrigin  = "same-origin"
+	ResourcePolicyCrossOrigin = "cross-origin"
+)
+
+var (
+	openerPolicies   = map[string]bool{OpenerPolicyUnsafeNone: true, OpenerPolicySameOriginAllowPopups: true, OpenerPolicySameOrigin: true, OpenerPolicyNoopenerAllowPopups: true}
+	embedderPolicies = map[string]bool{EmbedderPolicyUnsafeNone: true, EmbedderPolicyRequireCorp: true, EmbedderPolicyCredentialless: true}
+	resourcePolicies = map[string]bool{ResourcePolicySameSite: true, ResourcePolicySameOrigin: true, ResourcePolicyCrossOrigin: true}
+)
+
+// CrossOriginIsolationPolicy holds the values of the cross-origin isolation headers. Empty fields are not set,
+// or inherited from the service policy in a namespace config.
+type CrossOriginIsolationPolicy struct {
+	OpenerPolicy   string `json:"opener_policy,omitempty"`   // Cross-Origin-Opener-Policy
+	EmbedderPolicy string `json:"embedder_policy,omitempty"` // Cross-Origin-Embedder-Policy
+	ResourcePolicy string `json:"resource_policy,omitempty"` // Cross-Origin-Resource-Policy
+}
+
+// CrossOriginIsolated is the policy making documents cross-origin isolated, which browsers require to use
+// SharedArrayBuffer and high resolution timers, e.g. for multi-threaded web game builds.
+// The resources it embeds must be same-origin or opt in with CORS or Cross-Origin-Resource-Policy.
+func CrossOriginIsolated() CrossOriginIsolationPolicy {
+	return CrossOriginIsolationPolicy{
+		OpenerPolicy:   OpenerPolicySameOrigin,
+		EmbedderPolicy: EmbedderPolicyRequireCorp,
+		ResourcePolicy: ResourcePolicySameOrigin,
+	}
+}
+
+// merge returns policy with the non-empty fields of override.
+func (policy CrossOriginIsolationPolicy) merge(override *CrossOriginIsolationPolicy) CrossOriginIsolationPolicy {
+	if override == nil {
+		return policy
+	}
+	if override.OpenerPolicy != "" {
+		policy.OpenerPolicy = override.OpenerPolicy
+	}
+	if override.EmbedderPolicy != "" {
+		policy.EmbedderPolicy = override.EmbedderPolicy
+	}
+	if override.ResourcePolicy != "" {
+		policy.ResourcePolicy = override.ResourcePolicy
+	}
+	return policy
+}
+
+// mergeIsolation returns the service policy merged with the namespace policy, nil when both are nil.
+func mergeIsolation(service, ns *CrossOriginIsolationPolicy) *CrossOriginIsolationPolicy {
+	if service == nil && ns == nil {
+		return nil
+	}
+	policy := CrossOriginIsolationPolicy{}.merge(service).merge(ns)
+	return &policy
+}
+
+func (policy CrossOriginIsolationPolicy) setHeaders(resp *restful.Response) {
+	if policy.OpenerPolicy != "" {
+		resp.Header().Set(HeaderCrossOriginOpenerPolicy, policy.OpenerPolicy)
+	}
+	if policy.EmbedderPolicy != "" {
+		resp.Header().Set(HeaderCrossOriginEmbedderPolicy, policy.EmbedderPolicy)
+	}
+	if policy.ResourcePolicy != "" {
+		resp.Header().Set(HeaderCrossOriginResourcePolicy, policy.ResourcePolicy)
+	}
+}
+
+// CrossOriginIsolation is a filter setting the Cross-Origin-Opener-Policy, Cross-Origin-Embedder-Policy and
+// Cross-Origin-Resource-Policy headers on every response. Namespaces override the service Policy with the
+// cross_origin_isolation field of their CORS config, fetched by ConfigClient like the CORS filter does.
+// Use CrossOriginResourceSharing.CrossOriginIsolation to share the ConfigClient and namespace resolution
+// of a CORS filter.
+type CrossOriginIsolation struct {
+	Policy CrossOriginIsolationPolicy // service-level policy
+
+	// Namespace policies (optional - only the service policy is used when ConfigClient is nil)
+	ConfigClient       ConfigClient      // Client for fetching the namespace CORS configs
+	ConfigFetchTimeout time.Duration     // Per-request timeout for config service calls (default 200ms)
+	NamespaceResolver  NamespaceResolver // Resolves the namespace of the requests, required with ConfigClient
+	PublisherNamespace string            // Namespace of the requests without namespace
+
+	// cors is the CORS filter creating this one, its merged namespace configs are used instead of ConfigClient
+	cors *CrossOriginResourceSharing
+}
+
+// NewCrossOriginIsolation creates a filter setting policy on every response, without namespace policies.
+func NewCrossOriginIsolation(policy CrossOriginIsolationPolicy) *CrossOriginIsolation {
+	return &CrossOriginIsolation{Policy: policy}
+}
+
+// CrossOriginIsolation creates a cross-origin isolation filter with the service policy, reading the namespace
+// policies with the ConfigClient, NamespaceResolver, PublisherNamespace and ConfigFetchTimeout of c.
+// The namespace policies are read from the merged configs of c, so a namespace inheriting the config of its
+// studio or publisher inherits its policy too, and InvalidateNamespace applies to both filters.
+// It calls Init, so the fields of c must be set before.
+func (c *CrossOriginResourceSharing) CrossOriginIsolation(policy CrossOriginIsolationPolicy) *CrossOriginIsolation {
+	c.Init()
+	resolver := c.NamespaceResolver
+	if resolver == nil {
+		resolver = c.DefaultNamespaceResolver()
+	}
+	return &CrossOriginIsolation{
+		Policy:             policy,
+		ConfigClient:       c.ConfigClient,
+		ConfigFetchTimeout: c.ConfigFetchTimeout,
+		NamespaceResolver:  resolver,
+		PublisherNamespace: c.PublisherNamespace,
+		cors:               c,
+	}
+}
+
+// Filter sets the cross-origin isolation headers, then calls the next filter.
+// Handlers can still change them. The service policy is used when the namespace config can't be fetched.
+func (f *CrossOriginIsolation) Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	f.PolicyOf(req).setHeaders(resp)
+	chain.ProcessFilter(req, resp)
+}
+
+// PolicyOf returns the policy applied to req: the service Policy merged with the policy of the request namespace.
+func (f *CrossOriginIsolation) PolicyOf(req *restful.Request) CrossOriginIsolationPolicy {
+	if f.ConfigClient == nil {
+		return f.Policy
+	}
+
+	var namespace string
+	if f.NamespaceResolver != nil {
+		namespace = f.NamespaceResolver.ResolveNamespace(req)
+	}
+	if namespace == "" {
+		namespace = f.PublisherNamespace
+	}
+	if namespace == "" {
+		return f.Policy
+	}
+
+	policy, err := f.getNamespacePolicy(req.Request.Context(), namespace)
+	if errors.Is(err, ErrCircuitOpen) {
+		logrus.Debugf("Skipped fetching cross-origin isolation policy for namespace %s: %v", namespace, err)
+	} else if err != nil {
+		logrus.Errorf("Failed to fetch cross-origin isolation policy for namespace %s: %v", namespace, err)
+	}
+	return f.Policy.merge(policy)
+}
+
+// getNamespacePolicy returns the policy of namespace, from the merged config of the CORS filter when set.
+func (f *CrossOriginIsolation) getNamespacePolicy(ctx context.Context, namespace string) (*CrossOriginIsolationPolicy, error) {
+	if f.cors != nil {
+		config, _, err := f.cors.getNamespaceConfig(ctx, namespace)
+		if config == nil {
+			return nil, err
+		}
+		return config.CrossOriginIsolation, err
+	}
+
+	config, err := f.getCORSConfig(ctx, namespace)
+	if config == nil {
+		return nil, err
+	}
+	return config.CrossOriginIsolation, err
+}
+
+// getCORSConfig fetches the namespace config, bounded by ConfigFetchTimeout when the client supports contexts.
+func (f *CrossOriginIsolation) getCORSConfig(parent context.Context, namespace string) (*CORSConfigValue, error) {
+	client, ok := f.ConfigClient.(ContextConfigClient)
+	if !ok {
+		return f.ConfigClient.GetCORSConfig(namespace)
+	}
+
+	timeout := f.ConfigFetchTimeout
+	if timeout <= 0 {
+		timeout = defaultConfigFetchTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	return client.GetCORSConfigWithContext(ctx, namespace)
+}
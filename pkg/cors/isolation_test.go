@@ -0,0 +1,174 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	iam "github.com/AccelByte/iam-go-sdk/v2"
+	"github.com/emicklei/go-restful/v3"
+)
+
+func isolationHeadersOf(filter *CrossOriginIsolation, namespace string) (coop, coep, corp string, called bool) {
+	httpReq := httptest.NewRequest("GET", "/game/index.html", nil)
+	if namespace != "" {
+		httpReq.Header.Set(namespaceHeader, namespace)
+	}
+	resp := restful.NewResponse(httptest.NewRecorder())
+	filter.Filter(restful.NewRequest(httpReq), resp, createTestFilterChain(&called))
+	header := resp.Header()
+	return header.Get(HeaderCrossOriginOpenerPolicy), header.Get(HeaderCrossOriginEmbedderPolicy), header.Get(HeaderCrossOriginResourcePolicy), called
+}
+
+func TestCrossOriginIsolation_StaticPolicy(t *testing.T) {
+	filter := NewCrossOriginIsolation(CrossOriginIsolated())
+
+	coop, coep, corp, called := isolationHeadersOf(filter, "game1")
+	if coop != "same-origin" || coep != "require-corp" || corp != "same-origin" {
+		t.Errorf("Expected the cross-origin isolated headers, got %q %q %q", coop, coep, corp)
+	}
+	if !called {
+		t.Error("Expected the chain to be called")
+	}
+
+	filter = NewCrossOriginIsolation(CrossOriginIsolationPolicy{ResourcePolicy: ResourcePolicyCrossOrigin})
+	if coop, coep, corp, _ := isolationHeadersOf(filter, ""); coop != "" || coep != "" || corp != "cross-origin" {
+		t.Errorf("Expected only the resource policy, got %q %q %q", coop, coep, corp)
+	}
+}
+
+func TestCrossOriginIsolation_NamespacePolicy(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{
+		CrossOriginIsolation: &CrossOriginIsolationPolicy{EmbedderPolicy: EmbedderPolicyCredentialless},
+	}
+	mockClient.configs["publisher"] = &CORSConfigValue{
+		CrossOriginIsolation: &CrossOriginIsolationPolicy{OpenerPolicy: OpenerPolicyUnsafeNone},
+	}
+	mockClient.configs["game2"] = &CORSConfigValue{AllowedDomains: []string{"https://game2.io"}}
+	mockClient.errors["broken"] = errors.New("config service unavailable")
+
+	cors := &CrossOriginResourceSharing{ConfigClient: mockClient, PublisherNamespace: "publisher"}
+	filter := cors.CrossOriginIsolation(CrossOriginIsolated())
+
+	tests := []struct {
+		namespace        string
+		coop, coep, corp string
+	}{
+		{"game1", "same-origin", "credentialless", "same-origin"},
+		{"game2", "same-origin", "require-corp", "same-origin"},
+		{"unknown", "same-origin", "require-corp", "same-origin"},
+		{"broken", "same-origin", "require-corp", "same-origin"},
+		{"", "unsafe-none", "require-corp", "same-origin"},
+	}
+	for _, tt := range tests {
+		coop, coep, corp, _ := isolationHeadersOf(filter, tt.namespace)
+		if coop != tt.coop || coep != tt.coep || corp != tt.corp {
+			t.Errorf("namespace %q: expected %q %q %q, got %q %q %q", tt.namespace, tt.coop, tt.coep, tt.corp, coop, coep, corp)
+		}
+	}
+}
+
+func TestCrossOriginIsolation_SharesConfigClient(t *testing.T) {
+	cs, server := newCountingConfigServer()
+	defer server.Close()
+
+	cors := &CrossOriginResourceSharing{
+		AllowedDomains:   []string{"https://service.com"},
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+	}
+	filter := cors.CrossOriginIsolation(CrossOriginIsolated())
+	if filter.ConfigClient == nil || filter.ConfigClient != cors.ConfigClient {
+		t.Fatal("Expected the ConfigClient of the CORS filter")
+	}
+
+	isolationHeadersOf(filter, "game1")
+	if domains := allowedDomainsOf(cors, "game1"); !containsDomain(domains, "https://game1.io") {
+		t.Errorf("Expected the game1 config, got %v", domains)
+	}
+	if hits := cs.hitsOf("game1"); hits != 1 {
+		t.Errorf("Expected both filters to share the cached config, got %d calls", hits)
+	}
+}
+
+func TestCrossOriginIsolation_InheritsParentPolicy(t *testing.T) {
+	var mu sync.Mutex
+	embedderPolicy := EmbedderPolicyCredentialless
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/admin/namespaces/game1/configs/CORS") || strings.HasSuffix(r.URL.Path, "_SUBDOMAIN") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		value := `{"cross_origin_isolation": {"embedder_policy": "` + embedderPolicy + `"}}`
+		mu.Unlock()
+		// game1 has no config of its own and inherits the one of its studio
+		_ = json.NewEncoder(w).Encode(ConfigServiceResponse{Namespace: "studio1", Key: CORSConfigKey, Value: value})
+	}))
+	defer server.Close()
+
+	cors := &CrossOriginResourceSharing{
+		ConfigClient:       NewConfigClientWithIAM(server.URL, time.Hour, nil, TransportConfig{}),
+		PublisherNamespace: "publisher",
+	}
+	filter := cors.CrossOriginIsolation(CrossOriginIsolated())
+
+	if _, coep, _, _ := isolationHeadersOf(filter, "game1"); coep != "credentialless" {
+		t.Errorf("Expected the studio policy, got %q", coep)
+	}
+
+	mu.Lock()
+	embedderPolicy = EmbedderPolicyUnsafeNone
+	mu.Unlock()
+	cors.InvalidateNamespace("studio1")
+	if _, coep, _, _ := isolationHeadersOf(filter, "game1"); coep != "unsafe-none" {
+		t.Errorf("Expected the refreshed studio policy after invalidating the studio, got %q", coep)
+	}
+}
+
+func TestValidateCrossOriginIsolation(t *testing.T) {
+	validator := &ConfigValidator{Mode: ValidationSanitize}
+	config := &CORSConfigValue{CrossOriginIsolation: &CrossOriginIsolationPolicy{
+		OpenerPolicy:   OpenerPolicySameOrigin,
+		EmbedderPolicy: "require-corp; report-to=x",
+		ResourcePolicy: "everyone",
+	}}
+
+	sanitized, err := validator.Validate("game1", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := CrossOriginIsolationPolicy{OpenerPolicy: OpenerPolicySameOrigin}
+	if *sanitized.CrossOriginIsolation != expected {
+		t.Errorf("Expected the unknown policies to be removed, got %+v", *sanitized.CrossOriginIsolation)
+	}
+	if config.CrossOriginIsolation.ResourcePolicy != "everyone" {
+		t.Error("Expected the original config to be unchanged")
+	}
+
+	validator.Mode = ValidationReject
+	var validationErr *ConfigValidationError
+	if _, err := validator.Validate("game1", config); !errors.As(err, &validationErr) || len(validationErr.Issues) != 2 {
+		t.Errorf("Expected 2 validation issues, got %v", err)
+	}
+}
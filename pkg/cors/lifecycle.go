@@ -0,0 +1,143 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// warmUpConcurrency is the maximum number of namespaces prefetched at the same time by Start.
+const warmUpConcurrency = 8
+
+// Init creates the ConfigClient from ConfigServiceURL, ConfigCacheOptions and TransportConfig unless ConfigClient
+// is already set, and precompiles the static AllowedDomains and Diagnostics.DebugOrigins.
+// It runs once, later calls do nothing, so it is safe to call from concurrent requests.
+// Filter calls it on the first cross-origin request; set the fields before the first call.
+func (c *CrossOriginResourceSharing) Init() {
+	c.initOnce.Do(func() {
+		c.staticConfig = c.newStaticConfig()
+		c.debugMatcher = NewOriginMatcherSet(c.Diagnostics.DebugOrigins)
+		if c.ConfigClient == nil {
+			c.initConfigServiceClient()
+		} else if setter, ok := c.ConfigClient.(inheritedCookiesAllowedSetter); ok {
+			setter.setInheritedCookiesAllowed(c.CookiesAllowed)
+		}
+	})
+}
+
+// inheritedCookiesAllowedSetter is implemented by the clients validating their configs locally, so they validate
+// them with the CookiesAllowed of the filter like the client created from ConfigServiceURL does.
+type inheritedCookiesAllowedSetter interface {
+	setInheritedCookiesAllowed(cookiesAllowed bool)
+}
+
+// Start calls Init and prefetches the subdomain settings of PublisherNamespace and the configs of PublisherNamespace
+// and WarmUpNamespaces, so the first requests don't wait for the config service. Call it before the server accepts
+// traffic; the deadline of ctx bounds the whole prefetch instead of ConfigFetchTimeout.
+// The prefetch failures are returned joined. The filter is usable anyway, the failed namespaces are fetched again
+// on their first request.
+func (c *CrossOriginResourceSharing) Start(ctx context.Context) error {
+	c.Init()
+	if c.ConfigClient == nil {
+		return nil
+	}
+
+	var errs []error
+	if c.PublisherNamespace != "" {
+		if err := c.prefetchSubdomainConfig(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	namespaces := c.WarmUpNamespaces
+	if c.PublisherNamespace != "" {
+		namespaces = append([]string{c.PublisherNamespace}, namespaces...)
+	}
+	namespaces = dedup(namespaces)
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, warmUpConcurrency)
+	)
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(namespace string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := c.prefetchNamespace(ctx, namespace); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(namespace)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		logrus.Warnf("cors: prefetched CORS configs with %d failure(s)", len(errs))
+		return errors.Join(errs...)
+	}
+	logrus.Infof("cors: prefetched the CORS configs of %d namespace(s)", len(namespaces))
+	return nil
+}
+
+// prefetchNamespace loads the config of namespace into the ConfigClient cache and merges it, bounded by ctx only.
+func (c *CrossOriginResourceSharing) prefetchNamespace(ctx context.Context, namespace string) error {
+	var err error
+	if client, ok := c.ConfigClient.(ContextConfigClient); ok {
+		_, err = client.GetCORSConfigWithContext(ctx, namespace)
+	} else {
+		_, err = c.ConfigClient.GetCORSConfig(namespace)
+	}
+	if err == nil {
+		// served from the cache now, the merged config is cached too
+		_, _, err = c.getNamespaceConfig(ctx, namespace)
+	}
+	if err != nil {
+		return fmt.Errorf("cors: unable to prefetch CORS config of namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// prefetchSubdomainConfig loads the subdomain settings of PublisherNamespace, bounded by ctx only.
+func (c *CrossOriginResourceSharing) prefetchSubdomainConfig(ctx context.Context) error {
+	var cfg *CORSSubdomainConfig
+	var err error
+	if client, ok := c.ConfigClient.(ContextConfigClient); ok {
+		cfg, err = client.GetSubdomainConfigWithContext(ctx, c.PublisherNamespace)
+	} else {
+		cfg, err = c.ConfigClient.GetSubdomainConfig(c.PublisherNamespace)
+	}
+	if err != nil {
+		return fmt.Errorf("cors: unable to prefetch subdomain config of publisher namespace %s: %w", c.PublisherNamespace, err)
+	}
+
+	c.subdomainMu.Lock()
+	defer c.subdomainMu.Unlock()
+	c.setSubdomainConfig(cfg)
+	return nil
+}
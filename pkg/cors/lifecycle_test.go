@@ -0,0 +1,235 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	iam "github.com/AccelByte/iam-go-sdk/v2"
+	"github.com/emicklei/go-restful/v3"
+)
+
+// countingConfigServer returns an allowed domain per namespace and counts the config service calls.
+type countingConfigServer struct {
+	mu       sync.Mutex
+	hits     map[string]int
+	failing  map[string]bool
+	requests int32
+}
+
+func newCountingConfigServer() (*countingConfigServer, *httptest.Server) {
+	cs := &countingConfigServer{hits: make(map[string]int), failing: make(map[string]bool)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&cs.requests, 1)
+		namespace := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/admin/namespaces/"), "/")[0]
+		key := namespace
+		if strings.HasSuffix(r.URL.Path, "/configs/CORS_SUBDOMAIN") {
+			key = "subdomain:" + namespace
+		}
+
+		cs.mu.Lock()
+		cs.hits[key]++
+		failing := cs.failing[namespace]
+		cs.mu.Unlock()
+
+		switch {
+		case failing:
+			w.WriteHeader(http.StatusInternalServerError)
+		case key != namespace:
+			_ = json.NewEncoder(w).Encode(ConfigServiceResponse{
+				Namespace: namespace,
+				Key:       CORSSubdomainConfigKey,
+				Value:     `{"subdomain_enabled": true}`,
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(ConfigServiceResponse{
+				Namespace: namespace,
+				Key:       CORSConfigKey,
+				Value:     fmt.Sprintf(`{"allowed_domains": ["https://%s.io"]}`, namespace),
+			})
+		}
+	}))
+	return cs, server
+}
+
+func (cs *countingConfigServer) hitsOf(key string) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.hits[key]
+}
+
+func TestInit_ConcurrentFirstRequests(t *testing.T) {
+	_, server := newCountingConfigServer()
+	defer server.Close()
+
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:   []string{"https://service.com"},
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			httpReq := httptest.NewRequest("GET", "/", nil)
+			httpReq.Header.Set(restful.HEADER_Origin, "https://game1.io")
+			httpReq.Header.Set(namespaceHeader, "game1")
+			filter.Filter(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), createTestFilterChain(new(bool)))
+		}()
+	}
+	wg.Wait()
+
+	client := filter.ConfigClient
+	if client == nil {
+		t.Fatal("Expected the ConfigClient to be initialized")
+	}
+	filter.Init()
+	if filter.ConfigClient != client {
+		t.Error("Expected Init to create the ConfigClient once")
+	}
+}
+
+func TestInit_KeepsConfigClient(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	filter := &CrossOriginResourceSharing{
+		ConfigServiceURL: "http://test-config-service/config",
+		IAMClient:        iam.NewMockClient(),
+		ConfigClient:     mockClient,
+	}
+
+	filter.Init()
+	if filter.ConfigClient != mockClient {
+		t.Error("Expected Init to keep the ConfigClient set by the caller")
+	}
+}
+
+func TestInit_TransportConfig(t *testing.T) {
+	cs, server := newCountingConfigServer()
+	defer server.Close()
+	cs.failing["game1"] = true
+
+	filter := &CrossOriginResourceSharing{
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+		TransportConfig:  TransportConfig{MaxRetries: -1},
+	}
+	filter.Init()
+
+	if _, err := filter.ConfigClient.GetCORSConfig("game1"); err == nil {
+		t.Fatal("Expected the failing namespace to return an error")
+	}
+	if hits := cs.hitsOf("game1"); hits != 1 {
+		t.Errorf("Expected a single call without retries, got %d", hits)
+	}
+}
+
+func TestInit_ConfigCacheDefaults(t *testing.T) {
+	tests := []struct {
+		name               string
+		options            ConfigCacheOptions
+		staleTTL, errorTTL time.Duration
+	}{
+		{"defaults", ConfigCacheOptions{}, defaultConfigStaleTTL, defaultConfigErrorTTL},
+		{"set", ConfigCacheOptions{StaleTTL: time.Hour, ErrorTTL: time.Second}, time.Hour, time.Second},
+		{"disabled", ConfigCacheOptions{StaleTTL: -1, ErrorTTL: -1}, 0, 0},
+	}
+	for _, tt := range tests {
+		filter := &CrossOriginResourceSharing{
+			ConfigServiceURL:   "http://test-config-service/config",
+			IAMClient:          iam.NewMockClient(),
+			ConfigCacheOptions: tt.options,
+		}
+		filter.Init()
+
+		options := filter.ConfigClient.(*DefaultConfigClient).cache.options
+		if options.StaleTTL != tt.staleTTL || options.ErrorTTL != tt.errorTTL {
+			t.Errorf("%s: expected StaleTTL %v and ErrorTTL %v, got %v and %v", tt.name, tt.staleTTL, tt.errorTTL, options.StaleTTL, options.ErrorTTL)
+		}
+	}
+}
+
+func TestStart_WarmUp(t *testing.T) {
+	cs, server := newCountingConfigServer()
+	defer server.Close()
+
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:     []string{"https://service.com"},
+		ConfigServiceURL:   server.URL,
+		IAMClient:          iam.NewMockClient(),
+		PublisherNamespace: "publisher",
+		WarmUpNamespaces:   []string{"game1", "game2", "game1"},
+	}
+	if err := filter.Start(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"publisher", "subdomain:publisher", "game1", "game2"} {
+		if hits := cs.hitsOf(key); hits != 1 {
+			t.Errorf("Expected %s to be prefetched once, got %d calls", key, hits)
+		}
+	}
+	if enabled, _ := filter.getSubdomainSettings(); !enabled {
+		t.Error("Expected the prefetched subdomain settings")
+	}
+
+	// the warm namespaces are served from the cache
+	requests := atomic.LoadInt32(&cs.requests)
+	for _, namespace := range []string{"game1", "game2"} {
+		if domains := allowedDomainsOf(filter, namespace); !containsDomain(domains, "https://"+namespace+".io") {
+			t.Errorf("Expected the %s config, got %v", namespace, domains)
+		}
+	}
+	if calls := atomic.LoadInt32(&cs.requests) - requests; calls != 0 {
+		t.Errorf("Expected no config service call for the warm namespaces, got %d", calls)
+	}
+}
+
+func TestStart_WarmUpFailures(t *testing.T) {
+	cs, server := newCountingConfigServer()
+	defer server.Close()
+	cs.failing["broken"] = true
+
+	filter := &CrossOriginResourceSharing{
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+		TransportConfig:  TransportConfig{MaxRetries: -1},
+		WarmUpNamespaces: []string{"game1", "broken"},
+	}
+	err := filter.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "namespace broken") {
+		t.Fatalf("Expected the broken namespace prefetch error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "namespace game1") {
+		t.Errorf("Expected only the broken namespace to fail, got %v", err)
+	}
+}
+
+func TestStart_StaticConfig(t *testing.T) {
+	filter := &CrossOriginResourceSharing{AllowedDomains: []string{"https://service.com"}, WarmUpNamespaces: []string{"game1"}}
+	if err := filter.Start(context.Background()); err != nil {
+		t.Errorf("Expected no prefetch without config service, got %v", err)
+	}
+}
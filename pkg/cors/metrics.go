@@ -0,0 +1,380 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statuses of ConfigFetch calls that got no HTTP response.
+const (
+	FetchStatusError       = "error"        // network error
+	FetchStatusTimeout     = "timeout"      // the fetch timed out
+	FetchStatusCanceled    = "canceled"     // the caller cancelled the fetch
+	FetchStatusCircuitOpen = "circuit_open" // the circuit breaker rejected the fetch
+)
+
+// Metrics is notified of the CORS filter, config cache and config client activity.
+// Implementations must be safe for concurrent use. PrometheusMetrics is a ready-made implementation.
+type Metrics interface {
+	// ConfigCacheHit is called when a namespace config is served from the cache, fresh or stale.
+	ConfigCacheHit()
+	// ConfigCacheMiss is called when a namespace config has to be loaded.
+	ConfigCacheMiss()
+	// ConfigCacheEviction is called when a namespace config is evicted because the cache is full.
+	ConfigCacheEviction()
+	// ConfigFetch is called after each config service call with the config key (CORSConfigKey or
+	// CORSSubdomainConfigKey), the HTTP status code or one of the FetchStatus values, and the duration,
+	// retries included.
+	ConfigFetch(key, status string, duration time.Duration)
+	// StaticFallback is called when the config of namespace couldn't be fetched and the static config is used.
+	StaticFallback(namespace string)
+	// OriginDecision is called for every cross-origin request with its namespace, empty when none was resolved.
+	OriginDecision(namespace string, allowed bool)
+}
+
+// noopMetrics is used when no Metrics is configured.
+type noopMetrics struct{}
+
+func (noopMetrics) ConfigCacheHit()                           {}
+func (noopMetrics) ConfigCacheMiss()                          {}
+func (noopMetrics) ConfigCacheEviction()                      {}
+func (noopMetrics) ConfigFetch(string, string, time.Duration) {}
+func (noopMetrics) StaticFallback(string)                     {}
+func (noopMetrics) OriginDecision(string, bool)               {}
+
+func metricsOrNoop(metrics Metrics) Metrics {
+	if metrics == nil {
+		return noopMetrics{}
+	}
+	return metrics
+}
+
+// fetchStatus returns the ConfigFetch status of a config service call.
+func fetchStatus(resp *http.Response, err error) string {
+	switch {
+	case err == nil:
+		return strconv.Itoa(resp.StatusCode)
+	case errors.Is(err, ErrCircuitOpen):
+		return FetchStatusCircuitOpen
+	case errors.Is(err, context.DeadlineExceeded):
+		return FetchStatusTimeout
+	case errors.Is(err, context.Canceled):
+		return FetchStatusCanceled
+	default:
+		return FetchStatusError
+	}
+}
+
+const (
+	// defaultMetricsMaxNamespaces is the default PrometheusMetrics.MaxNamespaces.
+	defaultMetricsMaxNamespaces = 1000
+
+	// OtherNamespaceLabel is the namespace label of the series beyond PrometheusMetrics.MaxNamespaces.
+	OtherNamespaceLabel = "_other"
+
+	// PrometheusContentType is the content type of the Prometheus text exposition format.
+	PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// defaultFetchDurationBuckets are the Prometheus default histogram buckets, in seconds.
+var defaultFetchDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a Metrics keeping the counters and histograms in memory and writing them in the
+// Prometheus text exposition format, without a Prometheus client dependency. It serves them over HTTP, e.g.
+//
+//	metrics := cors.NewPrometheusMetrics()
+//	filter.Metrics = metrics
+//	http.Handle("/metrics", metrics)
+//
+// The series are:
+//
+//	cors_config_cache_hits_total
+//	cors_config_cache_misses_total
+//	cors_config_cache_evictions_total
+//	cors_config_fetch_duration_seconds{key,status} (histogram)
+//	cors_static_fallbacks_total{namespace}
+//	cors_origin_requests_total{namespace,result="allowed|rejected"}
+type PrometheusMetrics struct {
+	// MaxNamespaces bounds the number of namespace label values, the other namespaces are counted under
+	// OtherNamespaceLabel. The namespaces come from the requests, so they are not trusted (default 1000).
+	// Set it before the first call.
+	MaxNamespaces int
+
+	// Buckets are the upper bounds of the fetch duration histogram in seconds, sorted
+	// (default the Prometheus default buckets). Set it before the first call.
+	Buckets []float64
+
+	mu              sync.Mutex
+	cacheHits       uint64
+	cacheMisses     uint64
+	cacheEvictions  uint64
+	fetches         map[fetchSeries]*histogram
+	namespaces      map[string]struct{}
+	staticFallbacks map[string]uint64
+	originRequests  map[originSeries]uint64
+}
+
+type fetchSeries struct {
+	key    string
+	status string
+}
+
+type originSeries struct {
+	namespace string
+	allowed   bool
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics with the default settings.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{}
+}
+
+// ConfigCacheHit implements Metrics.
+func (m *PrometheusMetrics) ConfigCacheHit() {
+	m.mu.Lock()
+	m.cacheHits++
+	m.mu.Unlock()
+}
+
+// ConfigCacheMiss implements Metrics.
+func (m *PrometheusMetrics) ConfigCacheMiss() {
+	m.mu.Lock()
+	m.cacheMisses++
+	m.mu.Unlock()
+}
+
+// ConfigCacheEviction implements Metrics.
+func (m *PrometheusMetrics) ConfigCacheEviction() {
+	m.mu.Lock()
+	m.cacheEvictions++
+	m.mu.Unlock()
+}
+
+// ConfigFetch implements Metrics.
+func (m *PrometheusMetrics) ConfigFetch(key, status string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fetches == nil {
+		m.fetches = make(map[fetchSeries]*histogram)
+	}
+	buckets := m.buckets()
+	series := fetchSeries{key: key, status: status}
+	h, ok := m.fetches[series]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		m.fetches[series] = h
+	}
+
+	seconds := duration.Seconds()
+	if i := sort.SearchFloat64s(buckets, seconds); i < len(buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// StaticFallback implements Metrics.
+func (m *PrometheusMetrics) StaticFallback(namespace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.staticFallbacks == nil {
+		m.staticFallbacks = make(map[string]uint64)
+	}
+	m.staticFallbacks[m.namespaceLabel(namespace)]++
+}
+
+// OriginDecision implements Metrics.
+func (m *PrometheusMetrics) OriginDecision(namespace string, allowed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.originRequests == nil {
+		m.originRequests = make(map[originSeries]uint64)
+	}
+	m.originRequests[originSeries{namespace: m.namespaceLabel(namespace), allowed: allowed}]++
+}
+
+func (m *PrometheusMetrics) buckets() []float64 {
+	if len(m.Buckets) == 0 {
+		return defaultFetchDurationBuckets
+	}
+	return m.Buckets
+}
+
+// namespaceLabel returns the label value of namespace, OtherNamespaceLabel once MaxNamespaces are known.
+// The caller holds mu.
+func (m *PrometheusMetrics) namespaceLabel(namespace string) string {
+	if m.namespaces == nil {
+		m.namespaces = make(map[string]struct{})
+	}
+	if _, ok := m.namespaces[namespace]; ok {
+		return namespace
+	}
+	limit := m.MaxNamespaces
+	if limit <= 0 {
+		limit = defaultMetricsMaxNamespaces
+	}
+	if len(m.namespaces) >= limit {
+		return OtherNamespaceLabel
+	}
+	m.namespaces[namespace] = struct{}{}
+	return namespace
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format. Series are sorted by labels.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	m.mu.Lock()
+	writeCounterHeader(cw, "cors_config_cache_hits_total", "Number of CORS config lookups served from the cache.")
+	fmt.Fprintf(cw, "cors_config_cache_hits_total %d\n", m.cacheHits)
+	writeCounterHeader(cw, "cors_config_cache_misses_total", "Number of CORS config lookups loading the config.")
+	fmt.Fprintf(cw, "cors_config_cache_misses_total %d\n", m.cacheMisses)
+	writeCounterHeader(cw, "cors_config_cache_evictions_total", "Number of CORS configs evicted because the cache is full.")
+	fmt.Fprintf(cw, "cors_config_cache_evictions_total %d\n", m.cacheEvictions)
+	m.writeFetches(cw)
+	m.writeStaticFallbacks(cw)
+	m.writeOriginRequests(cw)
+	m.mu.Unlock()
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (m *PrometheusMetrics) writeFetches(w io.Writer) {
+	const name = "cors_config_fetch_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of the config service calls, retries included.\n# TYPE %s histogram\n", name, name)
+
+	series := make([]fetchSeries, 0, len(m.fetches))
+	for s := range m.fetches {
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].key != series[j].key {
+			return series[i].key < series[j].key
+		}
+		return series[i].status < series[j].status
+	})
+
+	buckets := m.buckets()
+	for _, s := range series {
+		h := m.fetches[s]
+		labels := fmt.Sprintf(`key="%s",status="%s"`, escapeLabelValue(s.key), escapeLabelValue(s.status))
+		var cumulative uint64
+		for i, bound := range buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+func (m *PrometheusMetrics) writeStaticFallbacks(w io.Writer) {
+	const name = "cors_static_fallbacks_total"
+	writeCounterHeader(w, name, "Number of requests using the static config because the namespace config couldn't be fetched.")
+
+	namespaces := make([]string, 0, len(m.staticFallbacks))
+	for namespace := range m.staticFallbacks {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		fmt.Fprintf(w, "%s{namespace=\"%s\"} %d\n", name, escapeLabelValue(namespace), m.staticFallbacks[namespace])
+	}
+}
+
+func (m *PrometheusMetrics) writeOriginRequests(w io.Writer) {
+	const name = "cors_origin_requests_total"
+	writeCounterHeader(w, name, "Number of cross-origin requests by namespace and result.")
+
+	series := make([]originSeries, 0, len(m.originRequests))
+	for s := range m.originRequests {
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].namespace != series[j].namespace {
+			return series[i].namespace < series[j].namespace
+		}
+		return series[i].allowed && !series[j].allowed
+	})
+	for _, s := range series {
+		result := "rejected"
+		if s.allowed {
+			result = "allowed"
+		}
+		fmt.Fprintf(w, "%s{namespace=\"%s\",result=\"%s\"} %d\n", name, escapeLabelValue(s.namespace), result, m.originRequests[s])
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", PrometheusContentType)
+	_, _ = m.WriteTo(w)
+}
+
+func writeCounterHeader(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value for the text exposition format.
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written and keeps the first error, so the writes don't need checks.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	iam "github.com/AccelByte/iam-go-sdk/v2"
+	"github.com/emicklei/go-restful/v3"
+)
+
+func TestPrometheusMetrics_WriteTo(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.Buckets = []float64{0.25, 1}
+	metrics.ConfigCacheHit()
+	metrics.ConfigCacheHit()
+	metrics.ConfigCacheMiss()
+	metrics.ConfigCacheEviction()
+	metrics.ConfigFetch(CORSConfigKey, "200", 250*time.Millisecond)
+	metrics.ConfigFetch(CORSConfigKey, "200", 2*time.Second)
+	metrics.ConfigFetch(CORSConfigKey, FetchStatusCircuitOpen, 0)
+	metrics.StaticFallback("game1")
+	metrics.OriginDecision("game1", false)
+	metrics.OriginDecision("game1", true)
+	metrics.OriginDecision("", true)
+	metrics.OriginDecision(`a"b\c`, true)
+
+	var out strings.Builder
+	n, err := metrics.WriteTo(&out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", out.Len(), n)
+	}
+
+	expected := `# HELP cors_config_cache_hits_total Number of CORS config lookups served from the cache.
+# TYPE cors_config_cache_hits_total counter
+cors_config_cache_hits_total 2
+# HELP cors_config_cache_misses_total Number of CORS config lookups loading the config.
+# TYPE cors_config_cache_misses_total counter
+cors_config_cache_misses_total 1
+# HELP cors_config_cache_evictions_total Number of CORS configs evicted because the cache is full.
+# TYPE cors_config_cache_evictions_total counter
+cors_config_cache_evictions_total 1
+# HELP cors_config_fetch_duration_seconds Duration of the config service calls, retries included.
+# TYPE cors_config_fetch_duration_seconds histogram
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="200",le="0.25"} 1
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="200",le="1"} 1
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="200",le="+Inf"} 2
+cors_config_fetch_duration_seconds_sum{key="CORS",status="200"} 2.25
+cors_config_fetch_duration_seconds_count{key="CORS",status="200"} 2
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="circuit_open",le="0.25"} 1
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="circuit_open",le="1"} 1
+cors_config_fetch_duration_seconds_bucket{key="CORS",status="circuit_open",le="+Inf"} 1
+cors_config_fetch_duration_seconds_sum{key="CORS",status="circuit_open"} 0
+cors_config_fetch_duration_seconds_count{key="CORS",status="circuit_open"} 1
+# HELP cors_static_fallbacks_total Number of requests using the static config because the namespace config couldn't be fetched.
+# TYPE cors_static_fallbacks_total counter
+cors_static_fallbacks_total{namespace="game1"} 1
+# HELP cors_origin_requests_total Number of cross-origin requests by namespace and result.
+# TYPE cors_origin_requests_total counter
+cors_origin_requests_total{namespace="",result="allowed"} 1
+cors_origin_requests_total{namespace="a\"b\\c",result="allowed"} 1
+cors_origin_requests_total{namespace="game1",result="allowed"} 1
+cors_origin_requests_total{namespace="game1",result="rejected"} 1
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestPrometheusMetrics_MaxNamespaces(t *testing.T) {
+	metrics := &PrometheusMetrics{MaxNamespaces: 2}
+	for _, namespace := range []string{"game1", "game2", "game3", "game4", "game1"} {
+		metrics.OriginDecision(namespace, true)
+	}
+
+	var out strings.Builder
+	_, _ = metrics.WriteTo(&out)
+	for _, line := range []string{
+		`cors_origin_requests_total{namespace="game1",result="allowed"} 2`,
+		`cors_origin_requests_total{namespace="game2",result="allowed"} 1`,
+		`cors_origin_requests_total{namespace="_other",result="allowed"} 2`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPrometheusMetrics_ServeHTTP(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.ConfigCacheMiss()
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if contentType := recorder.Header().Get("Content-Type"); contentType != PrometheusContentType {
+		t.Errorf("Expected the exposition content type, got %q", contentType)
+	}
+	if !strings.Contains(recorder.Body.String(), "cors_config_cache_misses_total 1\n") {
+		t.Errorf("Unexpected body:\n%s", recorder.Body.String())
+	}
+}
+
+// recordingMetrics counts the Metrics calls.
+type recordingMetrics struct {
+	noopMetrics
+	hits, misses, evictions int
+	fetches                 []string // key:status
+}
+
+func (m *recordingMetrics) ConfigCacheHit()      { m.hits++ }
+func (m *recordingMetrics) ConfigCacheMiss()     { m.misses++ }
+func (m *recordingMetrics) ConfigCacheEviction() { m.evictions++ }
+func (m *recordingMetrics) ConfigFetch(key, status string, _ time.Duration) {
+	m.fetches = append(m.fetches, key+":"+status)
+}
+
+func TestConfigCacheMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		return &CORSConfigValue{}, nil
+	}
+	cache, clock := newTestCache(ConfigCacheOptions{TTL: time.Minute, StaleTTL: time.Minute, Size: 2, Metrics: metrics}, loader)
+
+	cache.Get("ns1") // miss
+	cache.Get("ns1") // hit
+	clock.Advance(90 * time.Second)
+	cache.Get("ns1") // stale hit
+	waitForLoads(cache)
+	cache.Get("ns2") // miss
+	cache.Get("ns3") // miss, evicts ns1
+	cache.Invalidate("ns2")
+	cache.InvalidateAll()
+
+	if metrics.hits != 2 || metrics.misses != 3 {
+		t.Errorf("Expected 2 hits and 3 misses, got %d and %d", metrics.hits, metrics.misses)
+	}
+	if metrics.evictions != 1 {
+		t.Errorf("Expected only the LRU eviction to be counted, got %d", metrics.evictions)
+	}
+}
+
+func TestConfigClientMetrics(t *testing.T) {
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := NewConfigClientWithIAM(server.URL, time.Minute, nil, TransportConfig{
+		MaxRetries:     -1,
+		CircuitBreaker: CircuitBreakerConfig{WindowSize: 1, MinRequests: 1, FailureRatio: 1, Cooldown: time.Hour},
+		Metrics:        metrics,
+	})
+
+	_, _ = client.GetCORSConfig("game1")
+	failing = true
+	_, _ = client.GetSubdomainConfig("publisher")
+	_, _ = client.GetSubdomainConfig("publisher")
+
+	expected := []string{"CORS:404", "CORS_SUBDOMAIN:500", "CORS_SUBDOMAIN:circuit_open"}
+	if strings.Join(metrics.fetches, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected fetches %v, got %v", expected, metrics.fetches)
+	}
+}
+
+func TestFilterMetrics(t *testing.T) {
+	cs, server := newCountingConfigServer()
+	defer server.Close()
+	cs.failing["broken"] = true
+
+	metrics := NewPrometheusMetrics()
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:   []string{"https://service.com"},
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+		TransportConfig:  TransportConfig{MaxRetries: -1},
+		Metrics:          metrics,
+	}
+
+	for _, r := range []struct{ namespace, origin string }{
+		{"game1", "https://game1.io"},
+		{"game1", "https://game1.io"},
+		{"game1", "https://evil.io"},
+		{"broken", "https://service.com"},
+	} {
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Header.Set(restful.HEADER_Origin, r.origin)
+		httpReq.Header.Set(namespaceHeader, r.namespace)
+		filter.Filter(restful.NewRequest(httpReq), restful.NewResponse(httptest.NewRecorder()), createTestFilterChain(new(bool)))
+	}
+
+	var out strings.Builder
+	_, _ = metrics.WriteTo(&out)
+	for _, line := range []string{
+		`cors_config_cache_hits_total 2`,
+		`cors_config_cache_misses_total 2`,
+		`cors_config_fetch_duration_seconds_count{key="CORS",status="200"} 1`,
+		`cors_config_fetch_duration_seconds_count{key="CORS",status="500"} 1`,
+		`cors_static_fallbacks_total{namespace="broken"} 1`,
+		`cors_origin_requests_total{namespace="broken",result="allowed"} 1`,
+		`cors_origin_requests_total{namespace="game1",result="allowed"} 2`,
+		`cors_origin_requests_total{namespace="game1",result="rejected"} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, out.String())
+		}
+	}
+}
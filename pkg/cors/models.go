@@ -16,14 +16,29 @@ package cors
 
 // CORSConfigValue represents CORS configuration for a service or namespace.
 // List fields (AllowedDomains, AllowedHeaders, AllowedMethods, ExposeHeaders) are additive during merge.
-// Scalar fields (CookiesAllowed, MaxAge) use the namespace value as override if set (MaxAge=0 means "not set").
+// Scalar fields (CookiesAllowed, PrivateNetworkAllowed, MaxAge) use the namespace value as override if set:
+// the booleans are not set when nil (omitted from the JSON) and MaxAge=0 means "not set".
+// MergePolicy changes how each field is merged.
 type CORSConfigValue struct {
-	AllowedDomains []string `json:"allowed_domains"`
-	AllowedHeaders []string `json:"allowed_headers"`
-	AllowedMethods []string `json:"allowed_methods"`
-	ExposeHeaders  []string `json:"expose_headers"`
-	CookiesAllowed bool     `json:"cookies_allowed"`
-	MaxAge         int      `json:"max_age"` // 0 = "not set", use default
+	AllowedDomains        []string `json:"allowed_domains"`
+	AllowedHeaders        []string `json:"allowed_headers"`
+	AllowedMethods        []string `json:"allowed_methods"`
+	ExposeHeaders         []string `json:"expose_headers"`
+	CookiesAllowed        *bool    `json:"cookies_allowed,omitempty"`
+	PrivateNetworkAllowed *bool    `json:"private_network_allowed,omitempty"` // answer Private Network Access preflights
+	MaxAge                int      `json:"max_age"`                           // 0 = "not set", use default
+
+	// CrossOriginIsolation overrides the policy of the CrossOriginIsolation filter for the namespace (optional)
+	CrossOriginIsolation *CrossOriginIsolationPolicy `json:"cross_origin_isolation,omitempty"`
+
+	// SourceNamespace is the namespace defining the config, a parent (studio or publisher) namespace when the
+	// requested namespace inherits it. Empty when it is the requested namespace.
+	SourceNamespace string `json:"-"`
+}
+
+// Bool returns a pointer to v, to set the boolean fields of a CORSConfigValue.
+func Bool(v bool) *bool {
+	return &v
 }
 
 // ConfigServiceResponse represents the API response from the justice-config-service.
@@ -53,15 +68,33 @@ type ConfigServiceResponse struct {
 type CORSSubdomainConfig struct {
 	SubdomainEnabled    bool   `json:"subdomain_enabled"`
 	SubdomainBaseDomain string `json:"subdomain_base_domain"`
+
+	// CustomDomains maps tenant vanity domains to their namespace, e.g. "play.mygame.com" -> "mygame".
+	// They are resolved whether or not SubdomainEnabled is set.
+	CustomDomains map[string]string `json:"custom_domains,omitempty"`
 }
 
 // MergedCORSConfig is the result of merging service-level and namespace-level configs.
 // It combines the deduplicated lists from both sources with namespace scalars as overrides.
 type MergedCORSConfig struct {
-	AllowedDomains []string
-	AllowedHeaders []string
-	AllowedMethods []string
-	ExposeHeaders  []string
-	CookiesAllowed bool
-	MaxAge         int
+	AllowedDomains        []string
+	AllowedHeaders        []string
+	AllowedMethods        []string
+	ExposeHeaders         []string
+	CookiesAllowed        bool
+	PrivateNetworkAllowed bool
+	MaxAge                int
+
+	// DenyAllOrigins is set when MergeRestrict removed every namespace AllowedDomains entry. No origin is allowed
+	// then, while an empty AllowedDomains otherwise allows every origin.
+	DenyAllOrigins bool
+
+	// CrossOriginIsolation is the namespace policy merged over the service one, nil when neither has one
+	CrossOriginIsolation *CrossOriginIsolationPolicy
+
+	// originMatcher is the precompiled AllowedDomains, set when the config is cached by the filter
+	originMatcher *OriginMatcherSet
+
+	// sources are the configs merged into this one, from the least to the most specific, see Provenance
+	sources []configSource
 }
@@ -17,11 +17,80 @@ package cors
 import (
 	"strings"
 
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/hostmap"
 	"github.com/emicklei/go-restful/v3"
 )
 
 const namespaceHeader = "x-ab-rl-ns"
 
+// NamespaceResolver resolves the namespace of a request, e.g. to fetch its CORS config.
+// It returns an empty string when the request has no namespace.
+type NamespaceResolver interface {
+	ResolveNamespace(req *restful.Request) string
+}
+
+// NamespaceResolverFunc is a custom NamespaceResolver, e.g. reading the namespace from a token claim.
+type NamespaceResolverFunc func(req *restful.Request) string
+
+// ResolveNamespace calls f.
+func (f NamespaceResolverFunc) ResolveNamespace(req *restful.Request) string {
+	return f(req)
+}
+
+// ChainNamespaceResolvers returns a resolver trying resolvers in order and returning the first namespace found.
+func ChainNamespaceResolvers(resolvers ...NamespaceResolver) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		for _, resolver := range resolvers {
+			if ns := resolver.ResolveNamespace(req); ns != "" {
+				return ns
+			}
+		}
+		return ""
+	})
+}
+
+// PathParameterNamespaceResolver reads the namespace from the path parameter name, e.g. "namespace" for
+// routes like /namespaces/{namespace}/users.
+func PathParameterNamespaceResolver(name string) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		return req.PathParameter(name)
+	})
+}
+
+// HeaderNamespaceResolver reads the namespace from the request header name.
+func HeaderNamespaceResolver(name string) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		return req.Request.Header.Get(name)
+	})
+}
+
+// QueryParameterNamespaceResolver reads the namespace from the query parameter name.
+func QueryParameterNamespaceResolver(name string) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		if req.Request.URL == nil {
+			return ""
+		}
+		return req.Request.URL.Query().Get(name)
+	})
+}
+
+// SubdomainNamespaceResolver reads the namespace from the first label of the Host header, for hosts of at least
+// three labels. When baseDomain is non-empty, only hosts ending with ".<baseDomain>" are considered.
+func SubdomainNamespaceResolver(baseDomain string) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		return extractSubdomain(req, baseDomain)
+	})
+}
+
+// HostNamespaceResolver maps the Host header to a namespace with table, e.g. for multi-level subdomains and
+// vanity domains.
+func HostNamespaceResolver(table *hostmap.Table) NamespaceResolver {
+	return NamespaceResolverFunc(func(req *restful.Request) string {
+		ns, _ := table.Namespace(req.Request.Host)
+		return ns
+	})
+}
+
 // ExtractNamespace extracts the namespace from the request using the priority chain:
 // 1. Path parameter (highest priority)
 // 2. Subdomain (from Host header) — only when subdomainEnabled is true
@@ -16,8 +16,10 @@ package cors
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/hostmap"
 	"github.com/emicklei/go-restful/v3"
 )
 
@@ -151,3 +153,116 @@ func TestExtractSubdomain_WithPort(t *testing.T) {
 		t.Errorf("Expected 'api', got %q", result)
 	}
 }
+
+func TestNamespaceResolvers(t *testing.T) {
+	httpReq := httptest.NewRequest("GET", "http://game-ns.play.example.io/users?ns=query-ns", nil)
+	httpReq.Header.Set("X-Tenant", "header-ns")
+	req := restful.NewRequest(httpReq)
+	req.PathParameters()["studio"] = "path-ns"
+
+	tests := []struct {
+		name     string
+		resolver NamespaceResolver
+		expected string
+	}{
+		{"path parameter", PathParameterNamespaceResolver("studio"), "path-ns"},
+		{"missing path parameter", PathParameterNamespaceResolver("namespace"), ""},
+		{"header", HeaderNamespaceResolver("X-Tenant"), "header-ns"},
+		{"query parameter", QueryParameterNamespaceResolver("ns"), "query-ns"},
+		{"subdomain", SubdomainNamespaceResolver("example.io"), "game-ns"},
+		{"subdomain of another domain", SubdomainNamespaceResolver("accelbyte.io"), ""},
+		{"custom", NamespaceResolverFunc(func(*restful.Request) string { return "custom-ns" }), "custom-ns"},
+		{"chain", ChainNamespaceResolvers(
+			HeaderNamespaceResolver(namespaceHeader),
+			QueryParameterNamespaceResolver("ns"),
+			HeaderNamespaceResolver("X-Tenant"),
+		), "query-ns"},
+		{"empty chain", ChainNamespaceResolvers(), ""},
+	}
+
+	for _, tt := range tests {
+		if result := tt.resolver.ResolveNamespace(req); result != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, result)
+		}
+	}
+}
+
+func TestFilterNamespaceResolver(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://game1.io"}}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:    []string{"https://service.com"},
+		ConfigClient:      mockClient,
+		NamespaceResolver: QueryParameterNamespaceResolver("namespace"),
+	}
+
+	httpReq := httptest.NewRequest("GET", "/?namespace=game1", nil)
+	httpReq.Header.Set(namespaceHeader, "game2")
+	config, namespace, _ := filter.resolveDynamicConfig(restful.NewRequest(httpReq))
+	if namespace != "game1" || !containsDomain(config.AllowedDomains, "https://game1.io") {
+		t.Errorf("Expected the game1 config resolved from the query parameter, got %q %v", namespace, config.AllowedDomains)
+	}
+
+	// the default resolver reads the header
+	filter.NamespaceResolver = nil
+	_, namespace, _ = filter.resolveDynamicConfig(restful.NewRequest(httpReq))
+	if namespace != "game2" {
+		t.Errorf("Expected the default resolver to read the header, got %q", namespace)
+	}
+}
+
+func TestHostNamespaceResolver(t *testing.T) {
+	resolver := HostNamespaceResolver(&hostmap.Table{
+		Hosts:    hostmap.StaticRegistry{"play.mygame.com": "mygame"},
+		Suffixes: []hostmap.SuffixRule{{Suffix: "dev.accelbyte.io"}},
+	})
+
+	tests := map[string]string{
+		"ns.dev.accelbyte.io:8080": "ns",
+		"play.mygame.com":          "mygame",
+		"ns.accelbyte.io":          "",
+	}
+	for host, expected := range tests {
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Host = host
+		if result := resolver.ResolveNamespace(restful.NewRequest(httpReq)); result != expected {
+			t.Errorf("%s: expected %q, got %q", host, expected, result)
+		}
+	}
+}
+
+func TestDefaultNamespaceResolver_HostMapping(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.subdomainConfig = &CORSSubdomainConfig{
+		SubdomainEnabled: true,
+		CustomDomains:    map[string]string{"Play.MyGame.com": "mygame"},
+	}
+	filter := &CrossOriginResourceSharing{
+		ConfigClient:       mockClient,
+		PublisherNamespace: "publisher",
+		HostMapping:        &hostmap.Table{Suffixes: []hostmap.SuffixRule{{Suffix: "dev.accelbyte.io"}}},
+	}
+
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"ns.dev.accelbyte.io", "ns"},
+		{"play.mygame.com:443", "mygame"},
+		{"legacy.accelbyte.io", "legacy"},
+		{"example.com", ""},
+	}
+	for _, tt := range tests {
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Host = tt.host
+		if result := filter.DefaultNamespaceResolver().ResolveNamespace(restful.NewRequest(httpReq)); result != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.host, tt.expected, result)
+		}
+	}
+
+	// the custom domains can be shared with the IAM filter
+	table := &hostmap.Table{Registry: filter.CustomDomainRegistry()}
+	if namespace, _ := table.Namespace("play.mygame.com"); namespace != "mygame" {
+		t.Errorf("Expected the mygame custom domain, got %q", namespace)
+	}
+}
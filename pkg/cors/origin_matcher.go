@@ -0,0 +1,165 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OriginMatcherSet is a precompiled set of AllowedDomains patterns.
+// Exact patterns are looked up in a hash set, wildcard patterns in a trie of reversed host labels
+// and only regex and structured origin patterns are evaluated one by one, so matching cost doesn't grow
+// with the number of exact and wildcard patterns.
+// It matches the same origins as calling PatternMatcher.MatchOrigin for every pattern.
+type OriginMatcherSet struct {
+	allowAll  bool
+	exact     map[string]struct{}
+	wildcards map[string]*labelNode // keyed by the wildcard prefix, e.g. "https://"
+	linear    []*PatternMatcher     // regex and structured origin patterns, evaluated one by one
+}
+
+// labelNode is a trie node keyed by lowercase host labels, from the top level domain down.
+type labelNode struct {
+	children map[string]*labelNode
+	terminal bool
+}
+
+// NewOriginMatcherSet compiles the patterns. Invalid patterns are logged and skipped.
+// An empty pattern list allows every origin, like an empty AllowedDomains.
+func NewOriginMatcherSet(patterns []string) *OriginMatcherSet {
+	set := &OriginMatcherSet{
+		allowAll:  len(patterns) == 0,
+		exact:     make(map[string]struct{}),
+		wildcards: make(map[string]*labelNode),
+	}
+
+	for _, pattern := range patterns {
+		pm, err := Compile(pattern)
+		if err != nil {
+			logrus.Debugf("Invalid CORS domain pattern %q: %v", pattern, err)
+			continue
+		}
+
+		switch pm.Type {
+		case PatternTypeExact:
+			if pattern == "*" {
+				set.allowAll = true
+			}
+			set.exact[pattern] = struct{}{}
+		case PatternTypeWildcard:
+			set.addWildcard(pattern)
+		case PatternTypeRegex, PatternTypeOrigin:
+			set.linear = append(set.linear, pm)
+		}
+	}
+
+	return set
+}
+
+// addWildcard adds a <prefix>*.<suffix> pattern to the trie.
+// Patterns of any other shape never match in PatternMatcher, so they are skipped.
+func (set *OriginMatcherSet) addWildcard(pattern string) {
+	idx := strings.Index(pattern, "*")
+	if idx+2 > len(pattern) || pattern[idx+1] != '.' {
+		return
+	}
+
+	prefix := pattern[:idx]
+	suffix := pattern[idx+2:]
+	if colonIdx := strings.LastIndex(suffix, ":"); colonIdx != -1 {
+		suffix = suffix[:colonIdx]
+	}
+
+	node, ok := set.wildcards[prefix]
+	if !ok {
+		node = &labelNode{}
+		set.wildcards[prefix] = node
+	}
+
+	labels := strings.Split(strings.ToLower(suffix), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		if node.children == nil {
+			node.children = make(map[string]*labelNode)
+		}
+		child, ok := node.children[labels[i]]
+		if !ok {
+			child = &labelNode{}
+			node.children[labels[i]] = child
+		}
+		node = child
+	}
+	node.terminal = true
+}
+
+// MatchOrigin reports whether origin matches one of the patterns.
+func (set *OriginMatcherSet) MatchOrigin(origin string) bool {
+	if set == nil || len(origin) == 0 {
+		return false
+	}
+	if set.allowAll {
+		return true
+	}
+	if _, ok := set.exact[origin]; ok {
+		return true
+	}
+	if set.matchWildcard(origin) {
+		return true
+	}
+	for _, pm := range set.linear {
+		if pm.MatchOrigin(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchWildcard follows PatternMatcher.matchWildcard: the scheme must be equal, the port is ignored
+// and the origin host must have exactly one non-empty label more than the pattern suffix.
+func (set *OriginMatcherSet) matchWildcard(origin string) bool {
+	if len(set.wildcards) == 0 {
+		return false
+	}
+
+	originProtocol := ""
+	originHost := origin
+	if protocolIdx := strings.Index(origin, "://"); protocolIdx != -1 {
+		originProtocol = origin[:protocolIdx+3]
+		originHost = origin[protocolIdx+3:]
+	}
+	if colonIdx := strings.LastIndex(originHost, ":"); colonIdx != -1 {
+		originHost = originHost[:colonIdx]
+	}
+
+	node, ok := set.wildcards[originProtocol]
+	if !ok {
+		return false
+	}
+
+	labels := strings.Split(strings.ToLower(originHost), ".")
+	if len(labels) < 2 || labels[0] == "" {
+		return false
+	}
+	for i := len(labels) - 1; i >= 1; i-- {
+		node, ok = node.children[labels[i]]
+		if !ok {
+			return false
+		}
+	}
+
+	return node.terminal
+}
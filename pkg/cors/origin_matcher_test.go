@@ -0,0 +1,214 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// linearMatch is the reference behavior: every pattern compiled and matched one by one.
+func linearMatch(patterns []string, origin string) bool {
+	if len(origin) == 0 {
+		return false
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		pm, err := Compile(pattern)
+		if err != nil {
+			continue
+		}
+		if pm.MatchOrigin(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOriginMatcherSet_MatchesPatternMatcher(t *testing.T) {
+	patterns := []string{
+		"https://example.com",
+		"http://localhost:3000",
+		"https://*.accelbyte.io",
+		"https://*.game.example.io:8443",
+		"http://*.Dev.Example.org",
+		"*.no-scheme.io",
+		"https://api-*.example.io",
+		"re:^https://.*\\.regex\\.com$",
+		"re:[invalid",
+		"origin:http://localhost:*",
+		"origin:*://*.structured.io:3000-3999",
+		"origin:https://bücher.example",
+		"origin:https://bad host",
+	}
+	origins := []string{
+		"",
+		"https://example.com",
+		"https://EXAMPLE.com",
+		"http://example.com",
+		"http://localhost:3000",
+		"http://localhost:3001",
+		"https://game.accelbyte.io",
+		"https://GAME.AccelByte.IO",
+		"https://game.accelbyte.io:8080",
+		"https://accelbyte.io",
+		"https://a.b.accelbyte.io",
+		"http://game.accelbyte.io",
+		"https://.accelbyte.io",
+		"https://x.game.example.io",
+		"https://x.game.example.io:1",
+		"http://x.dev.example.org",
+		"sub.no-scheme.io",
+		"https://api-v1.example.io",
+		"https://sub.regex.com",
+		"https://sub.regex.com.evil.io",
+		"http://localhost",
+		"http://localhost:5173",
+		"https://localhost:5173",
+		"wss://game.structured.io:3100",
+		"https://game.structured.io",
+		"https://xn--bcher-kva.example",
+	}
+
+	set := NewOriginMatcherSet(patterns)
+	for _, origin := range origins {
+		expected := linearMatch(patterns, origin)
+		if actual := set.MatchOrigin(origin); actual != expected {
+			t.Errorf("MatchOrigin(%q) = %v, PatternMatcher gives %v", origin, actual, expected)
+		}
+	}
+}
+
+func TestOriginMatcherSet_AllowAll(t *testing.T) {
+	if !NewOriginMatcherSet(nil).MatchOrigin("https://any.com") {
+		t.Error("Expected empty pattern list to allow any origin")
+	}
+	if !NewOriginMatcherSet([]string{"https://example.com", "*"}).MatchOrigin("https://any.com") {
+		t.Error("Expected \"*\" to allow any origin")
+	}
+	if NewOriginMatcherSet([]string{"*"}).MatchOrigin("") {
+		t.Error("Expected empty origin to be rejected")
+	}
+}
+
+func TestFilterReusesMergedConfigUntilRefresh(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://*.game1.io"}}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		ConfigClient:   mockClient,
+	}
+
+	newRequest := func() *restful.Request {
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Header.Set(namespaceHeader, "game1")
+		return restful.NewRequest(httpReq)
+	}
+
+	first := filter.getConfigWithDynamicResolution(newRequest())
+	second := filter.getConfigWithDynamicResolution(newRequest())
+	if first != second {
+		t.Error("Expected merged config to be reused while the namespace config is unchanged")
+	}
+	if first.originMatcher == nil || !first.originMatcher.MatchOrigin("https://www.game1.io") {
+		t.Error("Expected merged config to carry a compiled origin matcher")
+	}
+
+	// the config client cache refreshed the entry
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://*.game1.net"}}
+	third := filter.getConfigWithDynamicResolution(newRequest())
+	if third == first {
+		t.Fatal("Expected merged config to be rebuilt after the namespace config changed")
+	}
+	if !third.originMatcher.MatchOrigin("https://www.game1.net") || third.originMatcher.MatchOrigin("https://www.game1.io") {
+		t.Error("Expected rebuilt matcher to use the refreshed namespace config")
+	}
+}
+
+func TestFilterBoundsMergedConfigs(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	filter := &CrossOriginResourceSharing{
+		ConfigClient:       mockClient,
+		ConfigCacheOptions: ConfigCacheOptions{Size: 2},
+	}
+
+	for i := 0; i < 5; i++ {
+		namespace := fmt.Sprintf("game%d", i)
+		mockClient.configs[namespace] = &CORSConfigValue{AllowedDomains: []string{"https://" + namespace + ".io"}}
+		httpReq := httptest.NewRequest("GET", "/", nil)
+		httpReq.Header.Set(namespaceHeader, namespace)
+		filter.getConfigWithDynamicResolution(restful.NewRequest(httpReq))
+	}
+
+	if size := filter.mergedConfigCache().Len(false); size != 2 {
+		t.Errorf("Expected the merged configs to be bounded by the config cache size, got %d entries", size)
+	}
+}
+
+func benchmarkPatterns(n int) []string {
+	patterns := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		switch i % 10 {
+		case 0:
+			patterns = append(patterns, fmt.Sprintf("re:^https://app%d-[a-z]+\\.regex\\.io$", i))
+		case 1, 2, 3:
+			patterns = append(patterns, fmt.Sprintf("https://*.studio%d.example.io", i))
+		default:
+			patterns = append(patterns, fmt.Sprintf("https://game%d.example.com", i))
+		}
+	}
+	return patterns
+}
+
+var benchmarkOrigins = []string{
+	"https://game4.example.com",
+	"https://www.studio1.example.io",
+	"https://unknown.example.net",
+}
+
+func BenchmarkOriginMatcherSet(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		set := NewOriginMatcherSet(benchmarkPatterns(n))
+		b.Run(fmt.Sprintf("patterns=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				set.MatchOrigin(benchmarkOrigins[i%len(benchmarkOrigins)])
+			}
+		})
+	}
+}
+
+func BenchmarkOriginMatcherSet_Compile(b *testing.B) {
+	patterns := benchmarkPatterns(500)
+	for i := 0; i < b.N; i++ {
+		NewOriginMatcherSet(patterns)
+	}
+}
+
+// BenchmarkLinearPatternMatch is the per request cost before the matchers were precompiled.
+func BenchmarkLinearPatternMatch(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		patterns := benchmarkPatterns(n)
+		b.Run(fmt.Sprintf("patterns=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearMatch(patterns, benchmarkOrigins[i%len(benchmarkOrigins)])
+			}
+		})
+	}
+}
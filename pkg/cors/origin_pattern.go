@@ -0,0 +1,234 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// originPatternPrefix marks the structured origin patterns, e.g. "origin:http://localhost:*".
+const originPatternPrefix = "origin:"
+
+// originPattern is a compiled structured origin pattern <scheme>://<host>[:<port>].
+type originPattern struct {
+	scheme   string // lowercase scheme, empty for any scheme
+	host     string // lowercase ASCII host, or the domain under the wildcard label
+	wildcard bool   // the host is "*.<host>", matching exactly one more label
+	anyPort  bool   // the port is "*", matching any port and no port
+	minPort  int    // port range, 0 when the origin must have no port
+	maxPort  int
+}
+
+// parseOriginPattern compiles the pattern after the "origin:" prefix. The syntax is:
+//
+//   - scheme: a scheme like https, or * for any scheme
+//   - host: a host name, an IPv4 address or a bracketed IPv6 address, matched case-insensitively.
+//     Internationalized names are converted to punycode. *.<domain> matches exactly one label more than domain.
+//   - port: absent to match origins without port, * for any port, a port like 8080 or a range like 3000-3999
+func parseOriginPattern(body string) (*originPattern, error) {
+	scheme, rest, ok := strings.Cut(body, "://")
+	if !ok {
+		return nil, errors.New("origin pattern must be <scheme>://<host>[:<port>]")
+	}
+	if strings.ContainsAny(rest, "/?#@") {
+		return nil, errors.New("origin pattern must not have a path, query, fragment or user info")
+	}
+
+	pattern := &originPattern{}
+	if scheme != "*" {
+		if !isValidScheme(scheme) {
+			return nil, fmt.Errorf("invalid scheme %q", scheme)
+		}
+		pattern.scheme = strings.ToLower(scheme)
+	}
+
+	host, port, err := splitOriginHostPort(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(host, "*.") {
+		pattern.wildcard = true
+		host = host[2:]
+	}
+	if strings.Contains(host, "*") {
+		return nil, errors.New("host wildcard must be a whole leftmost label followed by a domain, e.g. *.example.com")
+	}
+	if pattern.host, err = normalizeOriginHost(host); err != nil {
+		return nil, err
+	}
+	if pattern.wildcard && strings.HasPrefix(pattern.host, "[") {
+		return nil, errors.New("host wildcard can't be used with an IP address")
+	}
+
+	if err := pattern.parsePort(port); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+func (pattern *originPattern) parsePort(port string) error {
+	switch {
+	case port == "":
+		return nil
+	case port == "*":
+		pattern.anyPort = true
+		return nil
+	}
+
+	minPort, maxPort, isRange := strings.Cut(port, "-")
+	var err error
+	if pattern.minPort, err = parsePortNumber(minPort); err != nil {
+		return err
+	}
+	pattern.maxPort = pattern.minPort
+	if isRange {
+		if pattern.maxPort, err = parsePortNumber(maxPort); err != nil {
+			return err
+		}
+		if pattern.maxPort < pattern.minPort {
+			return fmt.Errorf("invalid port range %q, the first port is greater than the last", port)
+		}
+	}
+
+	return nil
+}
+
+func parsePortNumber(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 || s != strconv.Itoa(port) {
+		return 0, fmt.Errorf("invalid port %q, ports are 1 to 65535", s)
+	}
+	return port, nil
+}
+
+func (pattern *originPattern) match(origin string) bool {
+	scheme, rest, ok := strings.Cut(origin, "://")
+	if !ok || (pattern.scheme != "" && !strings.EqualFold(scheme, pattern.scheme)) {
+		return false
+	}
+
+	host, port, err := splitOriginHostPort(rest)
+	if err != nil {
+		return false
+	}
+	if host, err = normalizeOriginHost(host); err != nil {
+		return false
+	}
+
+	if pattern.wildcard {
+		label := strings.TrimSuffix(host, "."+pattern.host)
+		if label == host || label == "" || strings.Contains(label, ".") {
+			return false
+		}
+	} else if host != pattern.host {
+		return false
+	}
+
+	switch {
+	case pattern.anyPort:
+		return true
+	case port == "":
+		return pattern.minPort == 0
+	default:
+		number, err := parsePortNumber(port)
+		return err == nil && number >= pattern.minPort && number <= pattern.maxPort
+	}
+}
+
+// splitOriginHostPort splits <host>[:<port>], the host of an IPv6 address keeps its brackets.
+func splitOriginHostPort(hostPort string) (host, port string, err error) {
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.Index(hostPort, "]")
+		if end == -1 {
+			return "", "", errors.New("missing ] in IPv6 host")
+		}
+		host, rest := hostPort[:end+1], hostPort[end+1:]
+		if rest == "" {
+			return host, "", nil
+		}
+		if !strings.HasPrefix(rest, ":") || rest == ":" {
+			return "", "", fmt.Errorf("invalid port in %q", hostPort)
+		}
+		return host, rest[1:], nil
+	}
+
+	host, port, hasPort := strings.Cut(hostPort, ":")
+	if hasPort && port == "" {
+		return "", "", fmt.Errorf("invalid port in %q", hostPort)
+	}
+	return host, port, nil
+}
+
+// normalizeOriginHost returns host lowercase in ASCII, or an IPv6 address in its canonical bracketed form.
+func normalizeOriginHost(host string) (string, error) {
+	if strings.HasPrefix(host, "[") {
+		ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+		if ip == nil || ip.To4() != nil {
+			return "", fmt.Errorf("invalid IPv6 host %q", host)
+		}
+		return "[" + ip.String() + "]", nil
+	}
+
+	if host == "" {
+		return "", errors.New("empty host")
+	}
+	ascii, err := toASCIIHost(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if !isValidHostLabel(label) {
+			return "", fmt.Errorf("invalid host %q", host)
+		}
+	}
+
+	return ascii, nil
+}
+
+// isValidHostLabel reports whether label is a letter-digit-hyphen label of at most 63 characters.
+// Underscores are accepted, some internal hosts have them.
+func isValidHostLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidScheme reports whether scheme is a RFC 3986 scheme.
+func isValidScheme(scheme string) bool {
+	if scheme == "" {
+		return false
+	}
+	for i := 0; i < len(scheme); i++ {
+		c := scheme[i]
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if i == 0 && !letter || !(letter || c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileOriginPattern(t *testing.T) {
+	valid := []string{
+		"origin:https://example.com",
+		"origin:*://example.com",
+		"origin:http://localhost:*",
+		"origin:http://localhost:3000",
+		"origin:http://localhost:3000-3999",
+		"origin:https://*.example.io",
+		"origin:https://*.example.io:8443",
+		"origin:HTTPS://Example.COM",
+		"origin:https://bücher.example",
+		"origin:http://127.0.0.1:8080",
+		"origin:http://[::1]:*",
+		"origin:chrome-extension://abcdefghijklmnop",
+		"origin:https://internal_host.example.io",
+	}
+	for _, pattern := range valid {
+		pm, err := Compile(pattern)
+		if err != nil {
+			t.Errorf("Compile(%q) unexpected error: %v", pattern, err)
+			continue
+		}
+		if pm.Type != PatternTypeOrigin {
+			t.Errorf("Compile(%q) expected PatternTypeOrigin, got %v", pattern, pm.Type)
+		}
+	}
+
+	invalid := []string{
+		"origin:",
+		"origin:example.com",
+		"origin:://example.com",
+		"origin:1http://example.com",
+		"origin:ht tp://example.com",
+		"origin:https://",
+		"origin:https://example.com/",
+		"origin:https://example.com/path",
+		"origin:https://example.com?query",
+		"origin:https://user@example.com",
+		"origin:https://example.com:",
+		"origin:https://example.com:0",
+		"origin:https://example.com:65536",
+		"origin:https://example.com:http",
+		"origin:https://example.com:+80",
+		"origin:https://example.com:08",
+		"origin:https://example.com:3999-3000",
+		"origin:https://example.com:3000-",
+		"origin:https://example.com:*-3000",
+		"origin:https://*",
+		"origin:https://*.",
+		"origin:https://api-*.example.com",
+		"origin:https://*.*.example.com",
+		"origin:https://example.*.com",
+		"origin:https://exa mple.com",
+		"origin:https://example..com",
+		"origin:https://-example.com",
+		"origin:https://example.com.",
+		"origin:https://[::1",
+		"origin:https://[::1]x",
+		"origin:https://[not-an-ip]",
+		"origin:https://[127.0.0.1]",
+		"origin:https://*.[::1]",
+	}
+	for _, pattern := range invalid {
+		_, err := Compile(pattern)
+		var compilationErr *patternCompilationError
+		if !errors.As(err, &compilationErr) {
+			t.Errorf("Compile(%q) expected a pattern compilation error, got %v", pattern, err)
+		}
+	}
+}
+
+func TestMatchOriginStructured(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		origin   string
+		expected bool
+	}{
+		// scheme
+		{"origin:https://example.com", "https://example.com", true},
+		{"origin:https://example.com", "http://example.com", false},
+		{"origin:https://example.com", "HTTPS://example.com", true},
+		{"origin:*://example.com", "http://example.com", true},
+		{"origin:*://example.com", "wss://example.com", true},
+		{"origin:*://example.com", "example.com", false},
+		{"origin:https://example.com", "null", false},
+
+		// host
+		{"origin:https://example.com", "https://EXAMPLE.com", true},
+		{"origin:https://Example.COM", "https://example.com", true},
+		{"origin:https://example.com", "https://example.com.evil.io", false},
+		{"origin:https://example.com", "https://sub.example.com", false},
+		{"origin:https://*.example.io", "https://api.example.io", true},
+		{"origin:https://*.example.io", "https://API.Example.IO", true},
+		{"origin:https://*.example.io", "https://a.b.example.io", false},
+		{"origin:https://*.example.io", "https://example.io", false},
+		{"origin:https://*.example.io", "https://.example.io", false},
+		{"origin:https://*.example.io", "https://evilexample.io", false},
+
+		// IDN
+		{"origin:https://bücher.example", "https://xn--bcher-kva.example", true},
+		{"origin:https://BÜCHER.example", "https://xn--bcher-kva.example", true},
+		{"origin:https://xn--bcher-kva.example", "https://bücher.example", true},
+		{"origin:https://*.münchen.example", "https://shop.xn--mnchen-3ya.example", true},
+		{"origin:https://bücher.example", "https://bucher.example", false},
+
+		// port
+		{"origin:https://example.com", "https://example.com:8443", false},
+		{"origin:https://example.com:8443", "https://example.com", false},
+		{"origin:https://example.com:8443", "https://example.com:8443", true},
+		{"origin:https://example.com:8443", "https://example.com:8444", false},
+		{"origin:http://localhost:*", "http://localhost", true},
+		{"origin:http://localhost:*", "http://localhost:5173", true},
+		{"origin:http://localhost:*", "https://localhost:5173", false},
+		{"origin:http://localhost:*", "http://localhost.evil.io:5173", false},
+		{"origin:http://localhost:3000-3999", "http://localhost:3000", true},
+		{"origin:http://localhost:3000-3999", "http://localhost:3999", true},
+		{"origin:http://localhost:3000-3999", "http://localhost:4000", false},
+		{"origin:http://localhost:3000-3999", "http://localhost:2999", false},
+		{"origin:http://localhost:3000-3999", "http://localhost", false},
+		{"origin:http://localhost:3000-3999", "http://localhost:3000x", false},
+		{"origin:http://localhost:3000-3999", "http://localhost:03000", false},
+
+		// IP addresses
+		{"origin:http://127.0.0.1:*", "http://127.0.0.1:8080", true},
+		{"origin:http://[::1]:*", "http://[::1]:8080", true},
+		{"origin:http://[0:0::1]:*", "http://[::1]", true},
+		{"origin:http://[::1]:*", "http://[::2]:8080", false},
+
+		// paths are not part of an origin
+		{"origin:https://example.com", "https://example.com/", false},
+	}
+
+	for _, tt := range tests {
+		pm, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q) unexpected error: %v", tt.pattern, err)
+		}
+		if actual := pm.MatchOrigin(tt.origin); actual != tt.expected {
+			t.Errorf("%s MatchOrigin(%q) = %v, expected %v", tt.pattern, tt.origin, actual, tt.expected)
+		}
+	}
+}
+
+func TestToASCIIHost(t *testing.T) {
+	tests := map[string]string{
+		"example.com":            "example.com",
+		"Example.COM":            "example.com",
+		"bücher.example":         "xn--bcher-kva.example",
+		"münchen.de":             "xn--mnchen-3ya.de",
+		"例え.テスト":                 "xn--r8jz45g.xn--zckzah",
+		"MAJI-DE-KOI-SURU-5.jp":  "maji-de-koi-suru-5.jp",
+		"ñandú.example":          "xn--and-6ma2c.example",
+		"παράδειγμα.δοκιμή":      "xn--hxajbheg2az3al.xn--jxalpdlp",
+		"пример.испытание":       "xn--e1afmkfd.xn--80akhbyknj4f",
+		"straße.example":         "xn--strae-oqa.example",
+		"Café.Example":           "xn--caf-dma.example",
+		"shop.xn--mnchen-3ya.de": "shop.xn--mnchen-3ya.de",
+	}
+	for host, expected := range tests {
+		ascii, err := toASCIIHost(host)
+		if err != nil {
+			t.Errorf("toASCIIHost(%q) unexpected error: %v", host, err)
+			continue
+		}
+		if ascii != expected {
+			t.Errorf("toASCIIHost(%q) = %q, expected %q", host, ascii, expected)
+		}
+	}
+}
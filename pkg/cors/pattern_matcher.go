@@ -26,14 +26,17 @@ const (
 	PatternTypeExact PatternType = iota
 	PatternTypeWildcard
 	PatternTypeRegex
+	PatternTypeOrigin
 )
 
 // PatternMatcher matches origin values against configured CORS patterns.
-// Supports exact matching, wildcard matching (*.domain.io), and regex matching (re:pattern).
+// Supports exact matching, wildcard matching (*.domain.io), regex matching (re:pattern) and structured
+// origin matching (origin:pattern).
 type PatternMatcher struct {
 	Pattern string
 	Type    PatternType
 	regex   *regexp.Regexp
+	origin  *originPattern
 }
 
 // Compile creates a PatternMatcher from a pattern string.
@@ -42,10 +45,29 @@ type PatternMatcher struct {
 //   - "https://example.com" (exact match)
 //   - "https://*.example.io" (wildcard subdomain match)
 //   - "re:https://.*\.example\.com" (regex match)
+//   - "origin:*://*.example.io:3000-3999" (structured origin match, see below)
 //
 // No structural validation is performed on wildcard patterns — validation
 // of allowed values is the responsibility of the caller.
+//
+// Structured origin patterns are "origin:<scheme>://<host>[:<port>]" and are validated here:
+//   - scheme: a scheme like https, or * for any scheme
+//   - host: matched case-insensitively, internationalized names are converted to punycode, and a leading "*."
+//     label matches exactly one subdomain label
+//   - port: absent to match origins without port, * for any port, a port or a range like 3000-3999
 func Compile(pattern string) (*PatternMatcher, error) {
+	if strings.HasPrefix(pattern, originPatternPrefix) {
+		origin, err := parseOriginPattern(strings.TrimPrefix(pattern, originPatternPrefix))
+		if err != nil {
+			return nil, newPatternCompilationError(pattern, err)
+		}
+		return &PatternMatcher{
+			Pattern: pattern,
+			Type:    PatternTypeOrigin,
+			origin:  origin,
+		}, nil
+	}
+
 	// Check for regex prefix
 	if strings.HasPrefix(pattern, "re:") {
 		regexStr := strings.TrimPrefix(pattern, "re:")
@@ -91,6 +113,11 @@ func (pm *PatternMatcher) MatchOrigin(origin string) bool {
 			return false
 		}
 		return pm.regex.MatchString(origin)
+	case PatternTypeOrigin:
+		if pm.origin == nil {
+			return false
+		}
+		return pm.origin.match(origin)
 	default:
 		return false
 	}
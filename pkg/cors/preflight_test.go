@@ -0,0 +1,136 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func runPreflight(filter *CrossOriginResourceSharing, origin, method, headers string) (*httptest.ResponseRecorder, bool) {
+	httpReq := httptest.NewRequest(http.MethodOptions, "/", nil)
+	if origin != "" {
+		httpReq.Header.Set(restful.HEADER_Origin, origin)
+	}
+	httpReq.Header.Set(restful.HEADER_AccessControlRequestMethod, method)
+	if headers != "" {
+		httpReq.Header.Set(restful.HEADER_AccessControlRequestHeaders, headers)
+	}
+	recorder := httptest.NewRecorder()
+	called := false
+	filter.Filter(restful.NewRequest(httpReq), restful.NewResponse(recorder), createTestFilterChain(&called))
+	return recorder, called
+}
+
+func newPreflightTestFilter() *CrossOriginResourceSharing {
+	return &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+}
+
+func TestPreflightStatus(t *testing.T) {
+	filter := newPreflightTestFilter()
+
+	// allowed preflight: default 204 with CORS headers
+	recorder, called := runPreflight(filter, "https://example.com", "POST", "Content-Type")
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://example.com", recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+
+	// disallowed method: same status without CORS headers
+	recorder, called = runPreflight(filter, "https://example.com", "DELETE", "")
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+
+	// configured status
+	filter.PreflightStatus = http.StatusOK
+	recorder, _ = runPreflight(filter, "https://example.com", "POST", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRejectDisallowedPreflights(t *testing.T) {
+	filter := newPreflightTestFilter()
+	filter.RejectDisallowedPreflights = true
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+	}{
+		{"allowed", "https://example.com", "POST", "Content-Type", http.StatusNoContent},
+		{"method not allowed", "https://example.com", "DELETE", "", http.StatusForbidden},
+		{"header not allowed", "https://example.com", "POST", "X-Secret", http.StatusForbidden},
+		{"origin not allowed", "https://evil.com", "POST", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, called := runPreflight(filter, tt.origin, tt.method, tt.headers)
+			assert.False(t, called)
+			assert.Equal(t, tt.status, recorder.Code)
+			if tt.status == http.StatusForbidden {
+				assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+			}
+		})
+	}
+
+	// without the option a preflight from an unknown origin goes to the next filter
+	filter.RejectDisallowedPreflights = false
+	_, called := runPreflight(filter, "https://evil.com", "POST", "")
+	assert.True(t, called)
+}
+
+func TestVaryHeader(t *testing.T) {
+	filter := newPreflightTestFilter()
+	preflightVary := []string{"Origin, Access-Control-Request-Method, Access-Control-Request-Headers"}
+
+	recorder, _ := runPreflight(filter, "https://example.com", "POST", "")
+	assert.Equal(t, preflightVary, recorder.Header().Values("Vary"))
+
+	recorder, _ = runPreflight(filter, "https://evil.com", "POST", "")
+	assert.Equal(t, preflightVary, recorder.Header().Values("Vary"))
+
+	for _, origin := range []string{"https://example.com", "https://evil.com", ""} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		if origin != "" {
+			httpReq.Header.Set(restful.HEADER_Origin, origin)
+		}
+		recorder = httptest.NewRecorder()
+		called := false
+		filter.Filter(restful.NewRequest(httpReq), restful.NewResponse(recorder), createTestFilterChain(&called))
+		assert.Equal(t, []string{"Origin"}, recorder.Header().Values("Vary"), "origin %q", origin)
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := restful.NewResponse(recorder)
+	resp.Header().Set("Vary", "Accept-Encoding, origin")
+
+	addVary(resp, "Origin", "Access-Control-Request-Method")
+	assert.Equal(t, []string{"Accept-Encoding, origin", "Access-Control-Request-Method"}, recorder.Header().Values("Vary"))
+
+	resp.Header().Set("Vary", "*")
+	addVary(resp, "Origin")
+	assert.Equal(t, []string{"*"}, recorder.Header().Values("Vary"))
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConfigLevel is the level of a config merged into a MergedCORSConfig.
+type ConfigLevel string
+
+const (
+	ConfigLevelService    ConfigLevel = "service"    // static config of the filter
+	ConfigLevelPublisher  ConfigLevel = "publisher"  // config inherited from the publisher namespace
+	ConfigLevelStudio     ConfigLevel = "studio"     // config inherited from the studio namespace
+	ConfigLevelNamespace  ConfigLevel = "namespace"  // config of the game namespace itself
+	ConfigLevelWebService ConfigLevel = "webservice" // WebServicePolicies
+	ConfigLevelRoute      ConfigLevel = "route"      // RoutePolicy
+)
+
+// ConfigContributor is a config merged into a MergedCORSConfig.
+type ConfigContributor struct {
+	Level     ConfigLevel `json:"level"`
+	Namespace string      `json:"namespace,omitempty"` // namespace defining the config, for the namespace levels
+	Path      string      `json:"path,omitempty"`      // WebService root path or route path, for the policy levels
+}
+
+func (contributor ConfigContributor) String() string {
+	switch {
+	case contributor.Namespace != "":
+		return fmt.Sprintf("%s(%s)", contributor.Level, contributor.Namespace)
+	case contributor.Path != "":
+		return fmt.Sprintf("%s(%s)", contributor.Level, contributor.Path)
+	default:
+		return string(contributor.Level)
+	}
+}
+
+// configSource is a config merged into a MergedCORSConfig with its contributor.
+type configSource struct {
+	contributor ConfigContributor
+	value       *CORSConfigValue
+}
+
+// ProvenanceEntry is a value of a MergedCORSConfig field with the configs defining it.
+type ProvenanceEntry struct {
+	Field        string              `json:"field"` // JSON name of the field, e.g. "allowed_domains"
+	Value        string              `json:"value"`
+	Contributors []ConfigContributor `json:"contributors"`
+}
+
+// Provenance returns the configs each list entry and scalar value of config comes from.
+// A list entry lists every config containing it; a scalar lists the most specific config setting it to that value,
+// or no config when the value is the default.
+func (config *MergedCORSConfig) Provenance() []ProvenanceEntry {
+	sources := config.provenanceSources()
+
+	var entries []ProvenanceEntry
+	lists := []struct {
+		field  string
+		values []string
+		get    func(*CORSConfigValue) []string
+		equal  func(a, b string) bool
+	}{
+		{"allowed_domains", config.AllowedDomains, func(v *CORSConfigValue) []string { return v.AllowedDomains }, equalString},
+		{"allowed_headers", config.AllowedHeaders, func(v *CORSConfigValue) []string { return v.AllowedHeaders }, strings.EqualFold},
+		{"allowed_methods", config.AllowedMethods, func(v *CORSConfigValue) []string { return v.AllowedMethods }, equalString},
+		{"expose_headers", config.ExposeHeaders, func(v *CORSConfigValue) []string { return v.ExposeHeaders }, strings.EqualFold},
+	}
+	for _, list := range lists {
+		for _, value := range list.values {
+			entry := ProvenanceEntry{Field: list.field, Value: value}
+			for _, source := range sources {
+				if containsFunc(list.get(source.value), value, list.equal) {
+					entry.Contributors = append(entry.Contributors, source.contributor)
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	scalars := []struct {
+		field string
+		value string
+		isSet func(*CORSConfigValue) (string, bool)
+	}{
+		{"cookies_allowed", strconv.FormatBool(config.CookiesAllowed), func(v *CORSConfigValue) (string, bool) {
+			return formatBool(v.CookiesAllowed)
+		}},
+		{"private_network_allowed", strconv.FormatBool(config.PrivateNetworkAllowed), func(v *CORSConfigValue) (string, bool) {
+			return formatBool(v.PrivateNetworkAllowed)
+		}},
+		{"max_age", strconv.Itoa(config.MaxAge), func(v *CORSConfigValue) (string, bool) {
+			return strconv.Itoa(v.MaxAge), v.MaxAge > 0
+		}},
+	}
+	for _, scalar := range scalars {
+		entry := ProvenanceEntry{Field: scalar.field, Value: scalar.value}
+		for i := len(sources) - 1; i >= 0; i-- {
+			if value, ok := scalar.isSet(sources[i].value); ok && value == scalar.value {
+				entry.Contributors = []ConfigContributor{sources[i].contributor}
+				break
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// provenanceSources returns a copy of the sources of config, a config built without MergeConfigs is its own source.
+func (config *MergedCORSConfig) provenanceSources() []configSource {
+	if len(config.sources) == 0 {
+		return []configSource{{ConfigContributor{Level: ConfigLevelService}, config.value()}}
+	}
+
+	sources := make([]configSource, 0, len(config.sources))
+	for _, source := range config.sources {
+		if source.value != nil {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+func containsFunc(items []string, value string, equal func(a, b string) bool) bool {
+	for _, item := range items {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBool(value *bool) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	return strconv.FormatBool(*value), true
+}
+
+// namespaceContributor returns the contributor of the config fetched for namespace. A config inherited from
+// PublisherNamespace has the publisher level, a config inherited from any other parent the studio level.
+func (c *CrossOriginResourceSharing) namespaceContributor(namespace string, config *CORSConfigValue) ConfigContributor {
+	source := config.SourceNamespace
+	if source == "" {
+		source = namespace
+	}
+
+	switch {
+	case source == c.PublisherNamespace:
+		return ConfigContributor{Level: ConfigLevelPublisher, Namespace: source}
+	case source == namespace:
+		return ConfigContributor{Level: ConfigLevelNamespace, Namespace: source}
+	default:
+		return ConfigContributor{Level: ConfigLevelStudio, Namespace: source}
+	}
+}
+
+// ConfigInspection is the effective config of a namespace with the provenance of its entries.
+type ConfigInspection struct {
+	Namespace string            `json:"namespace"`
+	Source    ConfigSource      `json:"source"`
+	Error     string            `json:"error,omitempty"` // why the namespace config couldn't be fetched
+	Config    *CORSConfigValue  `json:"config"`
+	Entries   []ProvenanceEntry `json:"entries"`
+
+	// DenyAllOrigins tells that no origin is allowed, see MergedCORSConfig.DenyAllOrigins
+	DenyAllOrigins bool `json:"deny_all_origins,omitempty"`
+}
+
+// String formats the inspection one entry per line, e.g. for a support ticket.
+func (inspection *ConfigInspection) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "namespace %s (source: %s)\n", inspection.Namespace, inspection.Source)
+	if inspection.Error != "" {
+		fmt.Fprintf(&sb, "error: %s\n", inspection.Error)
+	}
+	if inspection.DenyAllOrigins {
+		sb.WriteString("no origin allowed: the service allows none of the namespace allowed_domains\n")
+	}
+	for _, entry := range inspection.Entries {
+		contributors := make([]string, 0, len(entry.Contributors))
+		for _, contributor := range entry.Contributors {
+			contributors = append(contributors, contributor.String())
+		}
+		if len(contributors) == 0 {
+			contributors = append(contributors, "default")
+		}
+		fmt.Fprintf(&sb, "%s %s <- %s\n", entry.Field, entry.Value, strings.Join(contributors, ", "))
+	}
+	return sb.String()
+}
+
+// InspectNamespace returns the effective config of namespace, before route policies, with the provenance of
+// its entries. The namespace config is fetched through the ConfigClient cache, like for a request.
+func (c *CrossOriginResourceSharing) InspectNamespace(ctx context.Context, namespace string) *ConfigInspection {
+	c.Init()
+
+	inspection := &ConfigInspection{Namespace: namespace, Source: ConfigSourceStatic}
+	var config *MergedCORSConfig
+	if c.ConfigClient != nil && namespace != "" {
+		var err error
+		config, inspection.Source, err = c.getNamespaceConfig(ctx, namespace)
+		if err != nil {
+			inspection.Error = err.Error()
+		}
+	}
+	if config == nil {
+		config = c.getStaticConfig()
+	}
+
+	inspection.Config = config.value()
+	inspection.DenyAllOrigins = config.DenyAllOrigins
+	inspection.Entries = config.Provenance()
+	return inspection
+}
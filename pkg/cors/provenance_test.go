@@ -0,0 +1,192 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// contributorsOf returns the contributors of the entry field=value, and false when there is no such entry.
+func contributorsOf(entries []ProvenanceEntry, field, value string) ([]string, bool) {
+	for _, entry := range entries {
+		if entry.Field == field && entry.Value == value {
+			contributors := []string{}
+			for _, contributor := range entry.Contributors {
+				contributors = append(contributors, contributor.String())
+			}
+			return contributors, true
+		}
+	}
+	return nil, false
+}
+
+func TestMergedConfigProvenance(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{
+		AllowedDomains:  []string{"https://service.com", "https://*.studio1.io"},
+		CookiesAllowed:  Bool(true),
+		SourceNamespace: "studio1",
+	}
+	mockClient.configs["game2"] = &CORSConfigValue{AllowedDomains: []string{"https://*.publisher.io"}, SourceNamespace: "publisher"}
+	mockClient.configs["game3"] = &CORSConfigValue{AllowedDomains: []string{"https://game3.io"}, MaxAge: 60}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:     []string{"https://service.com"},
+		AllowedMethods:     []string{"GET"},
+		MaxAge:             600,
+		ConfigClient:       mockClient,
+		PublisherNamespace: "publisher",
+	}
+
+	tests := []struct {
+		namespace string
+		field     string
+		value     string
+		expected  []string
+	}{
+		{"game1", "allowed_domains", "https://service.com", []string{"service", "studio(studio1)"}},
+		{"game1", "allowed_domains", "https://*.studio1.io", []string{"studio(studio1)"}},
+		{"game1", "allowed_methods", "GET", []string{"service"}},
+		{"game1", "cookies_allowed", "true", []string{"studio(studio1)"}},
+		{"game1", "private_network_allowed", "false", []string{"service"}},
+		{"game1", "max_age", "600", []string{"service"}},
+		{"game2", "allowed_domains", "https://*.publisher.io", []string{"publisher(publisher)"}},
+		{"game3", "allowed_domains", "https://game3.io", []string{"namespace(game3)"}},
+		{"game3", "max_age", "60", []string{"namespace(game3)"}},
+	}
+	for _, tt := range tests {
+		config, _, err := filter.getNamespaceConfig(context.Background(), tt.namespace)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		contributors, ok := contributorsOf(config.Provenance(), tt.field, tt.value)
+		if !ok {
+			t.Errorf("%s: no %s %s entry", tt.namespace, tt.field, tt.value)
+			continue
+		}
+		if !reflect.DeepEqual(contributors, tt.expected) {
+			t.Errorf("%s: %s %s contributors = %v, expected %v", tt.namespace, tt.field, tt.value, contributors, tt.expected)
+		}
+	}
+}
+
+func TestMergedConfigProvenance_RoutePolicies(t *testing.T) {
+	_, filter := newRoutePolicyContainer()
+
+	httpReq := httptest.NewRequest(http.MethodOptions, "/webhooks/github", nil)
+	httpReq.Header.Set(restful.HEADER_AccessControlRequestMethod, http.MethodPut)
+	config := filter.applyRoutePolicies(restful.NewRequest(httpReq), true, filter.getStaticConfig())
+
+	entries := config.Provenance()
+	if contributors, _ := contributorsOf(entries, "allowed_domains", "https://hooks.io"); !reflect.DeepEqual(contributors, []string{"webservice(/webhooks)"}) {
+		t.Errorf("Expected https://hooks.io from the /webhooks policy, got %v", contributors)
+	}
+	if contributors, _ := contributorsOf(entries, "allowed_domains", "https://service.com"); !reflect.DeepEqual(contributors, []string{"service"}) {
+		t.Errorf("Expected https://service.com from the service config, got %v", contributors)
+	}
+}
+
+func TestFetchFromServiceSourceNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// game1 has no config of its own and inherits the studio one
+		_ = json.NewEncoder(w).Encode(ConfigServiceResponse{
+			Namespace: "studio1",
+			Key:       CORSConfigKey,
+			Value:     `{"allowed_domains": ["https://*.studio1.io"]}`,
+		})
+	}))
+	defer server.Close()
+
+	client := NewConfigClientWithIAM(server.URL, time.Minute, nil, TransportConfig{})
+	config, err := client.GetCORSConfig("game1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.SourceNamespace != "studio1" {
+		t.Errorf("Expected SourceNamespace studio1, got %q", config.SourceNamespace)
+	}
+}
+
+func TestInspectNamespace(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://game1.io"}}
+	mockClient.errors["broken"] = errors.New("config service unavailable")
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		ConfigClient:   mockClient,
+	}
+
+	inspection := filter.InspectNamespace(context.Background(), "game1")
+	if inspection.Source != ConfigSourceNamespace || !containsDomain(inspection.Config.AllowedDomains, "https://game1.io") {
+		t.Errorf("Unexpected inspection: %+v", inspection)
+	}
+	if text := inspection.String(); !strings.Contains(text, "allowed_domains https://game1.io <- namespace(game1)") {
+		t.Errorf("Unexpected inspection text:\n%s", text)
+	}
+
+	inspection = filter.InspectNamespace(context.Background(), "broken")
+	if inspection.Source != ConfigSourceFallback || inspection.Error == "" || !containsDomain(inspection.Config.AllowedDomains, "https://service.com") {
+		t.Errorf("Expected the static config with the fetch error, got %+v", inspection)
+	}
+}
+
+func TestAdminWebServiceInspection(t *testing.T) {
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://game1.io"}}
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		ConfigClient:   mockClient,
+	}
+	adminService, err := filter.AdminWebService("/admin/cors", func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		chain.ProcessFilter(req, resp)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	container := restful.NewContainer()
+	container.Add(adminService)
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/cors/config/namespaces/game1", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	var inspection ConfigInspection
+	if err := json.Unmarshal(recorder.Body.Bytes(), &inspection); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if inspection.Namespace != "game1" || !containsDomain(inspection.Config.AllowedDomains, "https://game1.io") {
+		t.Errorf("Unexpected inspection: %+v", inspection)
+	}
+	if contributors, _ := contributorsOf(inspection.Entries, "allowed_domains", "https://service.com"); !reflect.DeepEqual(contributors, []string{"service"}) {
+		t.Errorf("Expected https://service.com from the service config, got %v", contributors)
+	}
+
+	recorder = httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/cors/config/namespaces/game1?format=text", nil))
+	if !strings.HasPrefix(recorder.Header().Get(restful.HEADER_ContentType), "text/plain") ||
+		!strings.Contains(recorder.Body.String(), "namespace game1 (source: namespace)") {
+		t.Errorf("Unexpected text inspection %q: %s", recorder.Header().Get(restful.HEADER_ContentType), recorder.Body.String())
+	}
+}
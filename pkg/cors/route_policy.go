@@ -0,0 +1,192 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"strings"
+
+	"github.com/bluele/gcache"
+	"github.com/emicklei/go-restful/v3"
+)
+
+// RoutePolicyMetadataKey is the route metadata key of a route CORS policy, a *CORSConfigValue.
+const RoutePolicyMetadataKey = "cors.policy"
+
+// routeMatcherCacheSize bounds the origin matchers compiled for configs with route policies.
+const routeMatcherCacheSize = 1000
+
+// RoutePolicy declares the CORS policy of a route, merged over the service, namespace and WebService configs:
+//
+//	ws.Route(ws.GET("/leaderboards").Do(cors.RoutePolicy(&cors.CORSConfigValue{...})).To(handler))
+func RoutePolicy(policy *CORSConfigValue) func(*restful.RouteBuilder) {
+	return func(b *restful.RouteBuilder) {
+		b.Metadata(RoutePolicyMetadataKey, policy)
+	}
+}
+
+// routeMatcherKey identifies the origin matcher of a config with route policies.
+type routeMatcherKey struct {
+	base       *OriginMatcherSet
+	webService *CORSConfigValue
+	route      *CORSConfigValue
+}
+
+// applyRoutePolicies merges the WebService and route policies of the request over config, using MergeConfigs
+// semantics: lists are combined and the policy scalars override the config ones.
+// Returns config when the request has no policy.
+func (c *CrossOriginResourceSharing) applyRoutePolicies(req *restful.Request, preflight bool, config *MergedCORSConfig) *MergedCORSConfig {
+	path, routePolicy := c.routePolicy(req, preflight)
+	if path == "" {
+		return config
+	}
+	webServicePath, webServicePolicy := c.webServicePolicy(path)
+	if webServicePolicy == nil && routePolicy == nil {
+		return config
+	}
+
+	merged := config
+	sources := config.provenanceSources()
+	for _, policy := range []configSource{
+		{ConfigContributor{Level: ConfigLevelWebService, Path: webServicePath}, webServicePolicy},
+		{ConfigContributor{Level: ConfigLevelRoute, Path: path}, routePolicy},
+	} {
+		if policy.value != nil {
+			merged = MergeConfigs(merged.value(), policy.value)
+			sources = append(sources, policy)
+		}
+	}
+	merged.sources = sources
+	// the policy domains are allowed on top of a config denying every origin
+	merged.DenyAllOrigins = config.DenyAllOrigins && len(merged.AllowedDomains) == 0
+	merged.originMatcher = c.getRouteOriginMatcher(routeMatcherKey{config.originMatcher, webServicePolicy, routePolicy}, merged.AllowedDomains)
+
+	return merged
+}
+
+// routePolicy returns the path of the route serving the request and its policy.
+// A preflight request matches no route, so the route serving the requested method is looked up in the Container.
+func (c *CrossOriginResourceSharing) routePolicy(req *restful.Request, preflight bool) (string, *CORSConfigValue) {
+	var path string
+	var metadata map[string]interface{}
+	if route := req.SelectedRoute(); route != nil {
+		path, metadata = route.Path(), route.Metadata()
+	} else if preflight && req.Request.URL != nil {
+		if route := c.findRoute(req.Request.Header.Get(restful.HEADER_AccessControlRequestMethod), req.Request.URL.Path); route != nil {
+			path, metadata = route.Path, route.Metadata
+		}
+	}
+
+	policy, _ := metadata[RoutePolicyMetadataKey].(*CORSConfigValue)
+	return path, policy
+}
+
+// webServicePolicy returns the policy of the WebService with the longest root path containing path, and that root path.
+func (c *CrossOriginResourceSharing) webServicePolicy(path string) (string, *CORSConfigValue) {
+	var policy *CORSConfigValue
+	var policyPath string
+	longest := -1
+	for rootPath, each := range c.WebServicePolicies {
+		trimmed := strings.TrimSuffix(rootPath, "/")
+		if len(trimmed) <= longest || !(path == trimmed || strings.HasPrefix(path, trimmed+"/")) {
+			continue
+		}
+		policy, policyPath, longest = each, rootPath, len(trimmed)
+	}
+	return policyPath, policy
+}
+
+// findRoute returns the route of the Container (restful.DefaultContainer when not set) serving method and path.
+// Literal path segments are preferred to path parameters, like the curly router does.
+func (c *CrossOriginResourceSharing) findRoute(method, path string) *restful.Route {
+	container := c.Container
+	if container == nil {
+		container = restful.DefaultContainer
+	}
+
+	requestTokens := splitPath(path)
+	var found *restful.Route
+	bestScore := -1
+	for _, ws := range container.RegisteredWebServices() {
+		routes := ws.Routes()
+		for i := range routes {
+			if routes[i].Method != method {
+				continue
+			}
+			if score, ok := matchRoutePath(splitPath(routes[i].Path), requestTokens); ok && score > bestScore {
+				found, bestScore = &routes[i], score
+			}
+		}
+	}
+	return found
+}
+
+// matchRoutePath matches the request path tokens against a route path template. The score is the number of
+// literal segments. A {param:*} segment matches the remaining segments.
+func matchRoutePath(templateTokens, requestTokens []string) (int, bool) {
+	score := 0
+	for i, token := range templateTokens {
+		isParam := strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}")
+		if isParam && strings.HasSuffix(token, ":*}") {
+			return score, true
+		}
+		if i >= len(requestTokens) {
+			return 0, false
+		}
+		if isParam {
+			continue
+		}
+		if token != requestTokens[i] {
+			return 0, false
+		}
+		score++
+	}
+	return score, len(templateTokens) == len(requestTokens)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// getRouteOriginMatcher returns the compiled domains of a config with route policies, cached by key
+// since the config itself is merged on every request.
+func (c *CrossOriginResourceSharing) getRouteOriginMatcher(key routeMatcherKey, domains []string) *OriginMatcherSet {
+	c.routeMatchersOnce.Do(func() {
+		c.routeMatchers = gcache.New(routeMatcherCacheSize).LRU().Build()
+	})
+	if v, err := c.routeMatchers.Get(key); err == nil {
+		return v.(*OriginMatcherSet)
+	}
+	matcher := NewOriginMatcherSet(domains)
+	_ = c.routeMatchers.Set(key, matcher)
+	return matcher
+}
+
+// value returns the config as a CORSConfigValue, to merge it with another config.
+func (config *MergedCORSConfig) value() *CORSConfigValue {
+	return &CORSConfigValue{
+		AllowedDomains:        config.AllowedDomains,
+		AllowedHeaders:        config.AllowedHeaders,
+		AllowedMethods:        config.AllowedMethods,
+		ExposeHeaders:         config.ExposeHeaders,
+		CookiesAllowed:        Bool(config.CookiesAllowed),
+		PrivateNetworkAllowed: Bool(config.PrivateNetworkAllowed),
+		MaxAge:                config.MaxAge,
+		CrossOriginIsolation:  config.CrossOriginIsolation,
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRoutePolicyContainer() (*restful.Container, *CrossOriginResourceSharing) {
+	container := restful.NewContainer()
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://service.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+		CookiesAllowed: true,
+		Container:      container,
+		WebServicePolicies: map[string]*CORSConfigValue{
+			"/webhooks": {AllowedDomains: []string{"https://hooks.io"}, AllowedMethods: []string{"PUT"}},
+		},
+	}
+	container.Filter(filter.Filter)
+
+	noop := func(req *restful.Request, resp *restful.Response) {}
+	ws := new(restful.WebService)
+	ws.Path("/api")
+	ws.Route(ws.GET("/leaderboards/{id}").
+		Do(RoutePolicy(&CORSConfigValue{AllowedDomains: []string{"*"}, CookiesAllowed: Bool(false)})).
+		To(noop))
+	ws.Route(ws.GET("/leaderboards/mine").To(noop))
+	ws.Route(ws.GET("/profile").To(noop))
+	container.Add(ws)
+
+	webhooks := new(restful.WebService)
+	webhooks.Path("/webhooks")
+	webhooks.Route(webhooks.PUT("/{provider}").To(noop))
+	container.Add(webhooks)
+
+	return container, filter
+}
+
+func serveCORS(container *restful.Container, method, path, origin, requestMethod string) *httptest.ResponseRecorder {
+	httpReq := httptest.NewRequest(method, path, nil)
+	httpReq.Header.Set(restful.HEADER_Origin, origin)
+	if requestMethod != "" {
+		httpReq.Header.Set(restful.HEADER_AccessControlRequestMethod, requestMethod)
+	}
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httpReq)
+	return recorder
+}
+
+func TestRoutePolicy(t *testing.T) {
+	container, _ := newRoutePolicyContainer()
+
+	recorder := serveCORS(container, http.MethodGet, "/api/leaderboards/top", "https://anyone.io", "")
+	assert.Equal(t, "https://anyone.io", recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+	// route policy scalars override the service ones when set, like namespace configs
+	assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowCredentials))
+
+	recorder = serveCORS(container, http.MethodGet, "/api/profile", "https://anyone.io", "")
+	assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+
+	recorder = serveCORS(container, http.MethodGet, "/api/profile", "https://service.com", "")
+	assert.Equal(t, "https://service.com", recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+	assert.Equal(t, "true", recorder.Header().Get(restful.HEADER_AccessControlAllowCredentials))
+}
+
+func TestRoutePolicy_Preflight(t *testing.T) {
+	container, _ := newRoutePolicyContainer()
+
+	recorder := serveCORS(container, http.MethodOptions, "/api/leaderboards/top", "https://anyone.io", "GET")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://anyone.io", recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+
+	// the literal route is preferred to the {id} route, and has no policy
+	recorder = serveCORS(container, http.MethodOptions, "/api/leaderboards/mine", "https://anyone.io", "GET")
+	assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+}
+
+func TestWebServicePolicy(t *testing.T) {
+	container, _ := newRoutePolicyContainer()
+
+	recorder := serveCORS(container, http.MethodOptions, "/webhooks/github", "https://hooks.io", "PUT")
+	assert.Equal(t, "https://hooks.io", recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+	assert.Equal(t, "GET,POST,PUT", recorder.Header().Get(restful.HEADER_AccessControlAllowMethods))
+
+	// the WebService policy doesn't apply to other web services
+	recorder = serveCORS(container, http.MethodOptions, "/api/profile", "https://hooks.io", "GET")
+	assert.Empty(t, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin))
+}
+
+func TestRoutePolicy_MergedWithNamespaceConfig(t *testing.T) {
+	container, filter := newRoutePolicyContainer()
+	mockClient := NewMockConfigClient()
+	mockClient.configs["game1"] = &CORSConfigValue{AllowedDomains: []string{"https://game1.io"}, CookiesAllowed: Bool(true)}
+	filter.ConfigClient = mockClient
+	filter.WebServicePolicies["/api"] = &CORSConfigValue{AllowedDomains: []string{"https://api-tools.io"}, CookiesAllowed: Bool(true)}
+
+	for _, origin := range []string{"https://game1.io", "https://api-tools.io", "https://service.com"} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+		httpReq.Header.Set(restful.HEADER_Origin, origin)
+		httpReq.Header.Set(namespaceHeader, "game1")
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, httpReq)
+		assert.Equal(t, origin, recorder.Header().Get(restful.HEADER_AccessControlAllowOrigin), origin)
+	}
+}
+
+func TestMatchRoutePath(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		score    int
+		match    bool
+	}{
+		{"/api/leaderboards/{id}", "/api/leaderboards/top", 2, true},
+		{"/api/leaderboards/mine", "/api/leaderboards/mine", 3, true},
+		{"/api/leaderboards/{id}", "/api/leaderboards", 0, false},
+		{"/api/leaderboards/{id}", "/api/leaderboards/top/entries", 0, false},
+		{"/static/{path:*}", "/static/css/app.css", 1, true},
+		{"/api/profile", "/api/other", 0, false},
+	}
+	for _, tt := range tests {
+		score, match := matchRoutePath(splitPath(tt.template), splitPath(tt.path))
+		if match != tt.match || (match && score != tt.score) {
+			t.Errorf("matchRoutePath(%q, %q) = %d, %v, expected %d, %v", tt.template, tt.path, score, match, tt.score, tt.match)
+		}
+	}
+}
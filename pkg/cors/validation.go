@@ -0,0 +1,271 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"fmt"
+	"os"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/AccelByte/go-restful-plugins/v4/pkg/constant"
+	"github.com/sirupsen/logrus"
+)
+
+// ValidationMode tells a ConfigValidator what to do with an unsafe config.
+type ValidationMode int
+
+const (
+	// ValidationSanitize removes the unsafe values and logs them, this is the default
+	ValidationSanitize ValidationMode = iota
+	// ValidationReject rejects the whole config with a *ConfigValidationError
+	ValidationReject
+	// ValidationDisabled accepts every config as is
+	ValidationDisabled
+)
+
+// defaultMaxRegexProgramSize bounds the compiled size of "re:" patterns. Go regexps run in linear time,
+// but a huge program still makes every match slow, e.g. "re:(a{1000}){1000}".
+const defaultMaxRegexProgramSize = 2000
+
+// corsMethods are the methods a config can allow. CONNECT and TRACE are forbidden by the Fetch standard.
+var corsMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// ConfigValidator checks the namespace configs loaded from the config service before they are cached.
+type ConfigValidator struct {
+	Mode ValidationMode
+
+	// RequireHTTPS rejects http:// origins, it is set in production realms by NewConfigValidatorFromEnv
+	RequireHTTPS bool
+
+	// MaxRegexProgramSize is the maximum compiled size of a "re:" pattern (default 2000 instructions)
+	MaxRegexProgramSize int
+
+	// InheritedCookiesAllowed is the cookies_allowed of the service config, which the namespace configs
+	// without cookies_allowed inherit. The filter sets it for the ConfigClient it creates from ConfigServiceURL.
+	InheritedCookiesAllowed bool
+}
+
+// NewConfigValidatorFromEnv returns a sanitizing validator requiring https origins unless REALM_NAME is set
+// and is not one of the live realms listed in REALM_LIVE (constant.DefaultRealmLive when unset).
+func NewConfigValidatorFromEnv() *ConfigValidator {
+	return &ConfigValidator{Mode: ValidationSanitize, RequireHTTPS: isProductionRealmFromEnv()}
+}
+
+func isProductionRealmFromEnv() bool {
+	realmName, ok := os.LookupEnv("REALM_NAME")
+	if !ok {
+		return true
+	}
+
+	realmLive, ok := os.LookupEnv("REALM_LIVE")
+	if !ok {
+		realmLive = constant.DefaultRealmLive
+	}
+	for _, live := range strings.Split(realmLive, ",") {
+		if realmName == live {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidationIssue is an unsafe value of a config.
+type ValidationIssue struct {
+	Field   string // JSON name of the field, e.g. "allowed_domains"
+	Value   string
+	Problem string
+}
+
+func (issue ValidationIssue) String() string {
+	return fmt.Sprintf("%s %q: %s", issue.Field, issue.Value, issue.Problem)
+}
+
+// ConfigValidationError lists the unsafe values of a namespace config.
+type ConfigValidationError struct {
+	Namespace string
+	Issues    []ValidationIssue
+}
+
+func (e *ConfigValidationError) Error() string {
+	issues := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		issues = append(issues, issue.String())
+	}
+	return fmt.Sprintf("invalid CORS config for namespace %q: %s", e.Namespace, strings.Join(issues, "; "))
+}
+
+// Validate checks config. Without issue config is returned as is. Otherwise, depending on the Mode,
+// a sanitized copy is returned and the issues are logged, or a *ConfigValidationError is returned.
+// A nil validator accepts every config.
+func (v *ConfigValidator) Validate(namespace string, config *CORSConfigValue) (*CORSConfigValue, error) {
+	if v == nil || v.Mode == ValidationDisabled || config == nil {
+		return config, nil
+	}
+
+	sanitized, issues := v.sanitize(config)
+	if len(issues) == 0 {
+		return config, nil
+	}
+
+	err := &ConfigValidationError{Namespace: namespace, Issues: issues}
+	if v.Mode == ValidationReject {
+		return nil, err
+	}
+
+	logrus.Warnf("cors: removed unsafe values: %v", err)
+	return sanitized, nil
+}
+
+// sanitize returns a copy of config without its unsafe values, and the issues found.
+func (v *ConfigValidator) sanitize(config *CORSConfigValue) (*CORSConfigValue, []ValidationIssue) {
+	var issues []ValidationIssue
+	sanitized := *config
+
+	cookiesAllowed := v.InheritedCookiesAllowed
+	if config.CookiesAllowed != nil {
+		cookiesAllowed = *config.CookiesAllowed
+	}
+
+	sanitized.AllowedDomains = nil
+	for _, domain := range config.AllowedDomains {
+		if problem := v.checkDomain(domain, cookiesAllowed); problem != "" {
+			issues = append(issues, ValidationIssue{Field: "allowed_domains", Value: domain, Problem: problem})
+			continue
+		}
+		sanitized.AllowedDomains = append(sanitized.AllowedDomains, domain)
+	}
+
+	sanitized.AllowedMethods = nil
+	for _, method := range config.AllowedMethods {
+		upper := strings.ToUpper(strings.TrimSpace(method))
+		switch {
+		case !corsMethods[upper]:
+			issues = append(issues, ValidationIssue{Field: "allowed_methods", Value: method, Problem: "unknown method"})
+			continue
+		case upper != method:
+			// methods are matched case-sensitively, "get" would never match
+			issues = append(issues, ValidationIssue{Field: "allowed_methods", Value: method, Problem: "method must be uppercase"})
+		}
+		sanitized.AllowedMethods = append(sanitized.AllowedMethods, upper)
+	}
+
+	if config.MaxAge < 0 {
+		issues = append(issues, ValidationIssue{Field: "max_age", Value: fmt.Sprint(config.MaxAge), Problem: "negative max age"})
+		sanitized.MaxAge = 0
+	}
+
+	if policy := config.CrossOriginIsolation; policy != nil {
+		sanitizedPolicy := *policy
+		for _, field := range []struct {
+			name   string
+			value  *string
+			values map[string]bool
+		}{
+			{"cross_origin_isolation.opener_policy", &sanitizedPolicy.OpenerPolicy, openerPolicies},
+			{"cross_origin_isolation.embedder_policy", &sanitizedPolicy.EmbedderPolicy, embedderPolicies},
+			{"cross_origin_isolation.resource_policy", &sanitizedPolicy.ResourcePolicy, resourcePolicies},
+		} {
+			if *field.value != "" && !field.values[*field.value] {
+				issues = append(issues, ValidationIssue{Field: field.name, Value: *field.value, Problem: "unknown policy"})
+				*field.value = ""
+			}
+		}
+		sanitized.CrossOriginIsolation = &sanitizedPolicy
+	}
+
+	return &sanitized, issues
+}
+
+// checkDomain returns why an allowed_domains pattern is unsafe, or an empty string.
+func (v *ConfigValidator) checkDomain(domain string, cookiesAllowed bool) string {
+	if domain == "*" {
+		if cookiesAllowed {
+			return "wildcard origin with cookies_allowed exposes credentialed responses to every site"
+		}
+		return ""
+	}
+
+	if strings.HasPrefix(domain, "re:") {
+		return v.checkRegex(strings.TrimPrefix(domain, "re:"))
+	}
+
+	if strings.HasPrefix(domain, originPatternPrefix) {
+		return v.checkOriginPattern(strings.TrimPrefix(domain, originPatternPrefix))
+	}
+
+	if v.RequireHTTPS && strings.HasPrefix(strings.ToLower(domain), "http://") {
+		return "non-https origin in a production realm"
+	}
+
+	if idx := strings.Index(domain, "*"); idx != -1 {
+		host := domain[idx+1:]
+		labelStart := idx == 0 || strings.HasSuffix(domain[:idx], "://")
+		if !labelStart || !strings.HasPrefix(host, ".") || strings.Contains(host, "*") {
+			return "wildcard must be a whole leftmost label, e.g. https://*.example.com"
+		}
+		if host = strings.SplitN(host[1:], ":", 2)[0]; !strings.Contains(host, ".") {
+			return "wildcard domain is too broad, the host after *. must contain a dot"
+		}
+	}
+
+	return ""
+}
+
+func (v *ConfigValidator) checkOriginPattern(body string) string {
+	pattern, err := parseOriginPattern(body)
+	if err != nil {
+		return fmt.Sprintf("invalid origin pattern: %v", err)
+	}
+	if v.RequireHTTPS && pattern.scheme != "https" {
+		return "origin pattern must use the https scheme in a production realm"
+	}
+	if pattern.wildcard && !strings.Contains(pattern.host, ".") {
+		return "wildcard domain is too broad, the host after *. must contain a dot"
+	}
+
+	return ""
+}
+
+func (v *ConfigValidator) checkRegex(expr string) string {
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return fmt.Sprintf("invalid regex: %v", err)
+	}
+	if !strings.HasPrefix(expr, "^") || !strings.HasSuffix(expr, "$") {
+		return "regex must be anchored with ^ and $"
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return fmt.Sprintf("invalid regex: %v", err)
+	}
+	maxSize := v.MaxRegexProgramSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxRegexProgramSize
+	}
+	if len(prog.Inst) > maxSize {
+		return fmt.Sprintf("regex is too complex (%d instructions, maximum %d)", len(prog.Inst), maxSize)
+	}
+
+	if v.RequireHTTPS && !strings.HasPrefix(strings.TrimPrefix(expr, "^"), "https://") {
+		return "regex must start with ^https:// in a production realm"
+	}
+
+	return ""
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	iam "github.com/AccelByte/iam-go-sdk/v2"
+)
+
+func TestConfigValidator_Domains(t *testing.T) {
+	validator := &ConfigValidator{RequireHTTPS: true}
+
+	tests := []struct {
+		domain         string
+		cookiesAllowed bool
+		problem        string // expected problem fragment, empty when valid
+	}{
+		{"https://example.com", true, ""},
+		{"https://*.example.com", true, ""},
+		{"https://*.example.com:8443", false, ""},
+		{"*", false, ""},
+		{"*", true, "wildcard origin with cookies_allowed"},
+		{"http://example.com", false, "non-https origin"},
+		{"HTTP://example.com", false, "non-https origin"},
+		{"https://*.io", false, "too broad"},
+		{"https://api-*.example.com", false, "whole leftmost label"},
+		{"https://*.*.example.com", false, "whole leftmost label"},
+		{`re:^https://[a-z]+\.example\.com$`, true, ""},
+		{`re:https://[a-z]+\.example\.com`, false, "anchored"},
+		{`re:^https://[a-z+\.example\.com$`, false, "invalid regex"},
+		{`re:^https://(a|b|c|d|e){1000}\.example\.com$`, false, "too complex"},
+		{`re:^https?://[a-z]+\.example\.com$`, false, "^https://"},
+		{"origin:https://*.example.com:*", true, ""},
+		{"origin:https://example.com:3000-3999", false, ""},
+		{"origin:*://example.com", false, "https scheme"},
+		{"origin:http://localhost:*", false, "https scheme"},
+		{"origin:https://*.io", false, "too broad"},
+		{"origin:https://example.com:0", false, "invalid origin pattern"},
+	}
+
+	for _, tt := range tests {
+		problem := validator.checkDomain(tt.domain, tt.cookiesAllowed)
+		if tt.problem == "" && problem != "" {
+			t.Errorf("Expected %q to be valid, got %q", tt.domain, problem)
+		}
+		if tt.problem != "" && !strings.Contains(problem, tt.problem) {
+			t.Errorf("Expected %q to be rejected with %q, got %q", tt.domain, tt.problem, problem)
+		}
+	}
+
+	// http origins are allowed outside production realms
+	validator.RequireHTTPS = false
+	for _, domain := range []string{"http://localhost:3000", "origin:http://localhost:*", "origin:*://example.com"} {
+		if problem := validator.checkDomain(domain, false); problem != "" {
+			t.Errorf("Expected %q to be valid outside production realms, got %q", domain, problem)
+		}
+	}
+}
+
+func TestConfigValidator_Sanitize(t *testing.T) {
+	validator := &ConfigValidator{Mode: ValidationSanitize, RequireHTTPS: true}
+	config := &CORSConfigValue{
+		AllowedDomains: []string{"*", "https://example.com", "http://example.com"},
+		AllowedMethods: []string{"get", "POST", "TRACE", "FETCH"},
+		AllowedHeaders: []string{"Content-Type"},
+		CookiesAllowed: Bool(true),
+		MaxAge:         -1,
+	}
+
+	sanitized, err := validator.Validate("game1", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &CORSConfigValue{
+		AllowedDomains: []string{"https://example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+		CookiesAllowed: Bool(true),
+	}
+	if !reflect.DeepEqual(sanitized, expected) {
+		t.Errorf("Expected sanitized config %+v, got %+v", expected, sanitized)
+	}
+	if len(config.AllowedDomains) != 3 {
+		t.Error("Expected the loaded config not to be modified")
+	}
+
+	valid := &CORSConfigValue{AllowedDomains: []string{"https://example.com"}, AllowedMethods: []string{"GET"}}
+	if result, _ := validator.Validate("game1", valid); result != valid {
+		t.Error("Expected a valid config to be returned as is")
+	}
+}
+
+func TestConfigValidator_Reject(t *testing.T) {
+	validator := &ConfigValidator{Mode: ValidationReject}
+	config := &CORSConfigValue{AllowedDomains: []string{"*"}, AllowedMethods: []string{"FETCH"}, CookiesAllowed: Bool(true)}
+
+	result, err := validator.Validate("game1", config)
+	if result != nil {
+		t.Errorf("Expected rejected config, got %+v", result)
+	}
+	var validationErr *ConfigValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ConfigValidationError, got %v", err)
+	}
+	if validationErr.Namespace != "game1" || len(validationErr.Issues) != 2 {
+		t.Errorf("Unexpected validation error %+v", validationErr)
+	}
+	expected := `invalid CORS config for namespace "game1": allowed_domains "*": wildcard origin with cookies_allowed ` +
+		`exposes credentialed responses to every site; allowed_methods "FETCH": unknown method`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestConfigValidator_Disabled(t *testing.T) {
+	config := &CORSConfigValue{AllowedDomains: []string{"*"}, CookiesAllowed: Bool(true)}
+	for _, validator := range []*ConfigValidator{nil, {Mode: ValidationDisabled}} {
+		if result, err := validator.Validate("game1", config); result != config || err != nil {
+			t.Errorf("Expected config to be accepted, got %+v, %v", result, err)
+		}
+	}
+}
+
+func TestProductionRealmFromEnv(t *testing.T) {
+	t.Setenv("REALM_LIVE", "prod,live")
+
+	t.Setenv("REALM_NAME", "prod")
+	if !isProductionRealmFromEnv() {
+		t.Error("Expected prod to be a production realm")
+	}
+	t.Setenv("REALM_NAME", "dev")
+	if isProductionRealmFromEnv() {
+		t.Error("Expected dev not to be a production realm")
+	}
+}
+
+func TestCacheValidatesLoadedConfig(t *testing.T) {
+	loader := func(ctx context.Context, ns string) (*CORSConfigValue, error) {
+		return &CORSConfigValue{AllowedDomains: []string{"*", "https://example.com"}, CookiesAllowed: Bool(true)}, nil
+	}
+
+	cache := NewConfigCacheWithOptions(ConfigCacheOptions{TTL: time.Minute}, loader)
+	result, err := cache.Get("game1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.AllowedDomains) != 1 || result.AllowedDomains[0] != "https://example.com" {
+		t.Errorf("Expected sanitized config to be cached, got %v", result.AllowedDomains)
+	}
+
+	cache = NewConfigCacheWithOptions(ConfigCacheOptions{TTL: time.Minute, Validator: &ConfigValidator{Mode: ValidationReject}}, loader)
+	var validationErr *ConfigValidationError
+	if _, err := cache.Get("game1"); !errors.As(err, &validationErr) {
+		t.Errorf("Expected ConfigValidationError, got %v", err)
+	}
+}
+
+func TestConfigValidator_InheritedCookiesAllowed(t *testing.T) {
+	validator := &ConfigValidator{InheritedCookiesAllowed: true}
+
+	sanitized, err := validator.Validate("game1", &CORSConfigValue{AllowedDomains: []string{"*", "https://example.com"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(sanitized.AllowedDomains, []string{"https://example.com"}) {
+		t.Errorf("Expected the wildcard to be removed with inherited cookies, got %v", sanitized.AllowedDomains)
+	}
+
+	config := &CORSConfigValue{AllowedDomains: []string{"*"}, CookiesAllowed: Bool(false)}
+	if sanitized, _ := validator.Validate("game1", config); sanitized != config {
+		t.Errorf("Expected the wildcard to be kept when the namespace disallows cookies, got %v", sanitized.AllowedDomains)
+	}
+}
+
+func TestFilterValidatesWithServiceCookiesAllowed(t *testing.T) {
+	cs, server := newConfigServer()
+	defer server.Close()
+	cs.setDomain("game1", "*")
+
+	filter := &CrossOriginResourceSharing{
+		AllowedDomains:   []string{"https://service.com"},
+		CookiesAllowed:   true,
+		ConfigServiceURL: server.URL,
+		IAMClient:        iam.NewMockClient(),
+	}
+	filter.Init()
+	if domains := allowedDomainsOf(filter, "game1"); containsDomain(domains, "*") {
+		t.Errorf("Expected the wildcard to be removed from a config inheriting cookies_allowed, got %v", domains)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostmap maps request hosts to namespaces, e.g. "ns.dev.accelbyte.io" or the vanity domain
+// "play.mygame.com". A Table is shared by the CORS filter and the IAM subdomain validation so both agree
+// on the namespace of a host.
+package hostmap
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// SuffixRule maps the hosts ending with ".<Suffix>" to one of the labels before Suffix.
+type SuffixRule struct {
+	Suffix string `json:"suffix"` // e.g. "dev.accelbyte.io"
+
+	// LabelIndex is the index of the namespace label among the labels before Suffix, from the left.
+	// A negative index counts from Suffix, -1 being the label right before it. E.g. for the host
+	// "api.ns.dev.accelbyte.io" and the suffix "dev.accelbyte.io", 0 is "api" and -1 is "ns".
+	LabelIndex int `json:"label_index"`
+}
+
+// namespace returns the namespace of the normalized host, and false when the rule doesn't apply to it.
+func (rule SuffixRule) namespace(host string) (string, bool) {
+	suffix := NormalizeHost(rule.Suffix)
+	if suffix == "" || !strings.HasSuffix(host, "."+suffix) {
+		return "", false
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, "."+suffix), ".")
+	index := rule.LabelIndex
+	if index < 0 {
+		index += len(labels)
+	}
+	if index < 0 || index >= len(labels) || labels[index] == "" {
+		return "", false
+	}
+
+	return labels[index], true
+}
+
+// ParseSuffixRules parses comma separated "<suffix>[:<label index>]" rules, e.g.
+// "dev.accelbyte.io,prod.example.io:-1". The label index defaults to 0.
+func ParseSuffixRules(s string) ([]SuffixRule, error) {
+	var rules []SuffixRule
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule := SuffixRule{Suffix: item}
+		if i := strings.LastIndex(item, ":"); i != -1 {
+			index, err := strconv.Atoi(item[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("hostmap: invalid label index in suffix rule %q: %w", item, err)
+			}
+			rule = SuffixRule{Suffix: item[:i], LabelIndex: index}
+		}
+		if NormalizeHost(rule.Suffix) == "" {
+			return nil, fmt.Errorf("hostmap: empty suffix in suffix rule %q", item)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// Registry looks up the namespace of custom domains, e.g. tenant vanity domains loaded from a config service.
+// Hosts are passed normalized, see NormalizeHost.
+type Registry interface {
+	LookupHost(host string) (namespace string, ok bool)
+}
+
+// RegistryFunc is a custom Registry.
+type RegistryFunc func(host string) (string, bool)
+
+// LookupHost calls f.
+func (f RegistryFunc) LookupHost(host string) (string, bool) {
+	return f(host)
+}
+
+// StaticRegistry is a Registry of fixed custom domains keyed by normalized host, see NewStaticRegistry.
+type StaticRegistry map[string]string
+
+// NewStaticRegistry returns the StaticRegistry of hosts with normalized keys, so they are matched
+// case-insensitively, without port and trailing dot.
+func NewStaticRegistry(hosts map[string]string) StaticRegistry {
+	registry := make(StaticRegistry, len(hosts))
+	for host, namespace := range hosts {
+		if host = NormalizeHost(host); host != "" {
+			registry[host] = namespace
+		}
+	}
+	return registry
+}
+
+// LookupHost returns the namespace of host.
+func (registry StaticRegistry) LookupHost(host string) (string, bool) {
+	namespace, ok := registry[host]
+	return namespace, ok
+}
+
+// Table maps hosts to namespaces. The exact Hosts are looked up first, then the Registry, then the Suffixes,
+// the longest matching suffix winning. The zero value maps no host.
+type Table struct {
+	Hosts    StaticRegistry // exact normalized hosts, e.g. "play.mygame.com" -> "mygame"
+	Registry Registry       // custom domains looked up after Hosts (optional)
+	Suffixes []SuffixRule
+}
+
+// Namespace returns the namespace of host, which can have a port. It returns false when no entry maps host.
+func (t *Table) Namespace(host string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	host = NormalizeHost(host)
+	if host == "" {
+		return "", false
+	}
+
+	if namespace, ok := t.Hosts.LookupHost(host); ok {
+		return namespace, true
+	}
+	if t.Registry != nil {
+		if namespace, ok := t.Registry.LookupHost(host); ok {
+			return namespace, true
+		}
+	}
+
+	namespace, matched := "", 0
+	for _, rule := range t.Suffixes {
+		if ns, ok := rule.namespace(host); ok && len(NormalizeHost(rule.Suffix)) > matched {
+			namespace, matched = ns, len(NormalizeHost(rule.Suffix))
+		}
+	}
+
+	return namespace, matched > 0
+}
+
+// NormalizeHost lowercases host and removes its port and trailing dot, e.g. "NS.Example.io.:8080" is
+// "ns.example.io".
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
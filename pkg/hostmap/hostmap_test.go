@@ -0,0 +1,109 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableNamespace(t *testing.T) {
+	table := &Table{
+		Hosts: NewStaticRegistry(map[string]string{"Play.MyGame.com.": "mygame"}),
+		Registry: RegistryFunc(func(host string) (string, bool) {
+			if host == "shop.othergame.net" {
+				return "othergame", true
+			}
+			return "", false
+		}),
+		Suffixes: []SuffixRule{
+			{Suffix: "accelbyte.io"},
+			{Suffix: "dev.accelbyte.io"},
+			{Suffix: "prod.example.io", LabelIndex: -1},
+		},
+	}
+
+	tests := []struct {
+		host      string
+		namespace string
+		ok        bool
+	}{
+		{"play.mygame.com", "mygame", true},
+		{"PLAY.mygame.com:443", "mygame", true},
+		{"shop.othergame.net", "othergame", true},
+		{"ns.accelbyte.io", "ns", true},
+		{"ns.dev.accelbyte.io", "ns", true}, // longest suffix wins
+		{"api.ns.prod.example.io", "ns", true},
+		{"ns.prod.example.io.", "ns", true},
+		{"accelbyte.io", "", false},
+		{"dev.accelbyte.io", "dev", true},
+		{"evilaccelbyte.io", "", false},
+		{"mygame.com", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		namespace, ok := table.Namespace(tt.host)
+		if namespace != tt.namespace || ok != tt.ok {
+			t.Errorf("Namespace(%q) = (%q, %v), expected (%q, %v)", tt.host, namespace, ok, tt.namespace, tt.ok)
+		}
+	}
+}
+
+func TestTableNamespace_Nil(t *testing.T) {
+	var table *Table
+	if _, ok := table.Namespace("ns.accelbyte.io"); ok {
+		t.Error("Expected a nil table to map no host")
+	}
+}
+
+func TestSuffixRuleLabelIndexOutOfRange(t *testing.T) {
+	table := &Table{Suffixes: []SuffixRule{{Suffix: "example.io", LabelIndex: 1}, {Suffix: "example.com", LabelIndex: -2}}}
+	for _, host := range []string{"ns.example.io", "ns.example.com"} {
+		if namespace, ok := table.Namespace(host); ok {
+			t.Errorf("Expected no namespace for %s, got %q", host, namespace)
+		}
+	}
+}
+
+func TestParseSuffixRules(t *testing.T) {
+	rules, err := ParseSuffixRules(" dev.accelbyte.io, prod.example.io:-1 ,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []SuffixRule{{Suffix: "dev.accelbyte.io"}, {Suffix: "prod.example.io", LabelIndex: -1}}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected %v, got %v", expected, rules)
+	}
+
+	for _, s := range []string{"example.io:first", ":1"} {
+		if _, err := ParseSuffixRules(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := map[string]string{
+		"NS.Example.io.:8080": "ns.example.io",
+		"ns.example.io":       "ns.example.io",
+		"[::1]:8080":          "::1",
+		" ns.example.io ":     "ns.example.io",
+	}
+	for host, expected := range tests {
+		if normalized := NormalizeHost(host); normalized != expected {
+			t.Errorf("NormalizeHost(%q) = %q, expected %q", host, normalized, expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secheaders provides a filter setting the security response headers:
+// Strict-Transport-Security, Content-Security-Policy, X-Frame-Options and Referrer-Policy.
+package secheaders
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderStrictTransportSecurity         = "Strict-Transport-Security"
+	HeaderContentSecurityPolicy           = "Content-Security-Policy"
+	HeaderContentSecurityPolicyReportOnly = "Content-Security-Policy-Report-Only"
+	HeaderFrameOptions                    = "X-Frame-Options"
+	HeaderReferrerPolicy                  = "Referrer-Policy"
+
+	// NoncePlaceholder is replaced by the request nonce in ContentSecurityPolicy,
+	// e.g. "script-src 'self' 'nonce-{nonce}'"
+	NoncePlaceholder = "{nonce}"
+
+	// NonceAttribute is the request attribute key of the CSP nonce, set when the policy has a NoncePlaceholder
+	NonceAttribute = "CSPNonce"
+
+	// RouteOverrideMetadataKey is the route metadata key of an Override
+	RouteOverrideMetadataKey = "secheaders.override"
+
+	nonceSize = 16
+)
+
+// Default header values of DefaultConfig.
+const (
+	DefaultHSTSMaxAge            = 365 * 24 * time.Hour
+	DefaultContentSecurityPolicy = "default-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'"
+	DefaultFrameOptions          = "DENY"
+	DefaultReferrerPolicy        = "strict-origin-when-cross-origin"
+)
+
+// Config holds the security headers. A header with an empty value (or a zero HSTSMaxAge) is not set.
+type Config struct {
+	// Strict-Transport-Security: max-age=<HSTSMaxAge in seconds>[; includeSubDomains][; preload]
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	// ContentSecurityPolicy is the Content-Security-Policy. Its NoncePlaceholder are replaced by a nonce
+	// generated for each request, stored in the request under NonceAttribute.
+	ContentSecurityPolicy string
+	// ReportOnly sends the policy as Content-Security-Policy-Report-Only: violations are reported to the
+	// report-uri or report-to directive of the policy, not blocked. Use it to try a policy out.
+	ReportOnly bool
+
+	FrameOptions   string // X-Frame-Options, e.g. DENY or SAMEORIGIN
+	ReferrerPolicy string // Referrer-Policy, e.g. no-referrer
+}
+
+// DefaultConfig returns the default headers: a one year HSTS including the subdomains, a CSP allowing same origin
+// resources only and forbidding framing, X-Frame-Options DENY and the strict-origin-when-cross-origin referrer policy.
+func DefaultConfig() Config {
+	return Config{
+		HSTSMaxAge:            DefaultHSTSMaxAge,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: DefaultContentSecurityPolicy,
+		FrameOptions:          DefaultFrameOptions,
+		ReferrerPolicy:        DefaultReferrerPolicy,
+	}
+}
+
+// Override changes the Config of a route, see RouteOverride.
+type Override func(config *Config)
+
+// RouteOverride declares how the headers of a route differ from the filter Config, e.g. to let a page be framed:
+//
+//	ws.Route(ws.GET("/embed").Do(secheaders.RouteOverride(func(c *secheaders.Config) {
+//		c.FrameOptions = ""
+//		c.ContentSecurityPolicy = "frame-ancestors https://portal.example.io"
+//	})).To(handler))
+//
+// go-restful selects the route before running the filters, so the override also applies to a Container filter.
+// A request matching no route gets the filter Config.
+func RouteOverride(override Override) func(*restful.RouteBuilder) {
+	return func(b *restful.RouteBuilder) {
+		b.Metadata(RouteOverrideMetadataKey, override)
+	}
+}
+
+// Filter sets the DefaultConfig headers.
+func Filter() restful.FilterFunction {
+	return FilterWithConfig(DefaultConfig())
+}
+
+// FilterWithConfig sets the headers of config, changed by the Override of the route, before calling the next filter.
+// Handlers can still change them.
+func FilterWithConfig(config Config) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		routeConfig := config
+		if route := req.SelectedRoute(); route != nil {
+			if override, ok := route.Metadata()[RouteOverrideMetadataKey].(Override); ok && override != nil {
+				override(&routeConfig)
+			}
+		}
+
+		routeConfig.setHeaders(req, resp)
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// Nonce returns the CSP nonce of the request, or an empty string when the policy has no NoncePlaceholder.
+func Nonce(req *restful.Request) string {
+	nonce, _ := req.Attribute(NonceAttribute).(string)
+	return nonce
+}
+
+func (config Config) setHeaders(req *restful.Request, resp *restful.Response) {
+	header := resp.Header()
+
+	if hsts := config.hstsValue(); hsts != "" {
+		header.Set(HeaderStrictTransportSecurity, hsts)
+	}
+
+	if policy := config.ContentSecurityPolicy; policy != "" {
+		if strings.Contains(policy, NoncePlaceholder) {
+			nonce, err := generateNonce()
+			if err != nil {
+				// the policy is kept with an empty nonce, so the nonce sources are blocked
+				logrus.Errorf("Unable to generate CSP nonce %s", err.Error())
+			}
+			req.SetAttribute(NonceAttribute, nonce)
+			policy = strings.ReplaceAll(policy, NoncePlaceholder, nonce)
+		}
+
+		if config.ReportOnly {
+			header.Set(HeaderContentSecurityPolicyReportOnly, policy)
+		} else {
+			header.Set(HeaderContentSecurityPolicy, policy)
+		}
+	}
+
+	if config.FrameOptions != "" {
+		header.Set(HeaderFrameOptions, config.FrameOptions)
+	}
+	if config.ReferrerPolicy != "" {
+		header.Set(HeaderReferrerPolicy, config.ReferrerPolicy)
+	}
+}
+
+func (config Config) hstsValue() string {
+	if config.HSTSMaxAge <= 0 {
+		return ""
+	}
+	value := fmt.Sprintf("max-age=%d", int64(config.HSTSMaxAge/time.Second))
+	if config.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if config.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+// generateNonce returns a random base64 nonce of nonceSize bytes.
+func generateNonce() (string, error) {
+	b := make([]byte, nonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 AccelByte Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secheaders
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve sends a GET request to path through a WebService with filter, returning the response and the request nonce.
+func serve(filter restful.FilterFunction, path string) (*httptest.ResponseRecorder, string) {
+	var nonce string
+	handler := func(req *restful.Request, resp *restful.Response) {
+		nonce = Nonce(req)
+	}
+
+	ws := new(restful.WebService)
+	ws.Filter(filter)
+	ws.Route(ws.GET("/page").To(handler))
+	ws.Route(ws.GET("/embed").
+		Do(RouteOverride(func(c *Config) {
+			c.FrameOptions = ""
+			c.ContentSecurityPolicy = "frame-ancestors https://portal.example.io"
+		})).
+		To(handler))
+
+	container := restful.NewContainer()
+	container.Add(ws)
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder, nonce
+}
+
+func TestFilter_Defaults(t *testing.T) {
+	t.Parallel()
+
+	recorder, nonce := serve(Filter(), "/page")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "max-age=31536000; includeSubDomains", recorder.Header().Get(HeaderStrictTransportSecurity))
+	assert.Equal(t, DefaultContentSecurityPolicy, recorder.Header().Get(HeaderContentSecurityPolicy))
+	assert.Empty(t, recorder.Header().Get(HeaderContentSecurityPolicyReportOnly))
+	assert.Equal(t, "DENY", recorder.Header().Get(HeaderFrameOptions))
+	assert.Equal(t, "strict-origin-when-cross-origin", recorder.Header().Get(HeaderReferrerPolicy))
+	assert.Empty(t, nonce)
+}
+
+func TestFilterWithConfig(t *testing.T) {
+	t.Parallel()
+
+	recorder, _ := serve(FilterWithConfig(Config{
+		HSTSMaxAge:     2 * time.Hour,
+		HSTSPreload:    true,
+		ReferrerPolicy: "no-referrer",
+	}), "/page")
+
+	assert.Equal(t, "max-age=7200; preload", recorder.Header().Get(HeaderStrictTransportSecurity))
+	assert.Equal(t, "no-referrer", recorder.Header().Get(HeaderReferrerPolicy))
+	assert.Empty(t, recorder.Header().Get(HeaderContentSecurityPolicy))
+	assert.Empty(t, recorder.Header().Get(HeaderFrameOptions))
+}
+
+func TestFilter_Nonce(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	config.ContentSecurityPolicy = "script-src 'self' 'nonce-{nonce}'; style-src 'nonce-{nonce}'"
+	filter := FilterWithConfig(config)
+
+	recorder, nonce := serve(filter, "/page")
+	decoded, err := base64.StdEncoding.DecodeString(nonce)
+	assert.NoError(t, err)
+	assert.Len(t, decoded, nonceSize)
+	assert.Equal(t, "script-src 'self' 'nonce-"+nonce+"'; style-src 'nonce-"+nonce+"'",
+		recorder.Header().Get(HeaderContentSecurityPolicy))
+
+	_, other := serve(filter, "/page")
+	assert.NotEqual(t, nonce, other, "Expected a nonce per request")
+}
+
+func TestFilter_ReportOnly(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	config.ContentSecurityPolicy = "default-src 'self'; report-uri /csp-reports"
+	config.ReportOnly = true
+
+	recorder, _ := serve(FilterWithConfig(config), "/page")
+
+	assert.Empty(t, recorder.Header().Get(HeaderContentSecurityPolicy))
+	assert.Equal(t, "default-src 'self'; report-uri /csp-reports", recorder.Header().Get(HeaderContentSecurityPolicyReportOnly))
+}
+
+func TestFilter_RouteOverride(t *testing.T) {
+	t.Parallel()
+
+	filter := Filter()
+	recorder, _ := serve(filter, "/embed")
+
+	assert.Empty(t, recorder.Header().Get(HeaderFrameOptions))
+	assert.Equal(t, "frame-ancestors https://portal.example.io", recorder.Header().Get(HeaderContentSecurityPolicy))
+	assert.Equal(t, "strict-origin-when-cross-origin", recorder.Header().Get(HeaderReferrerPolicy))
+
+	// the override doesn't change the filter config
+	recorder, _ = serve(filter, "/page")
+	assert.Equal(t, "DENY", recorder.Header().Get(HeaderFrameOptions))
+	assert.Equal(t, DefaultContentSecurityPolicy, recorder.Header().Get(HeaderContentSecurityPolicy))
+}
+
+func TestFilter_RouteOverrideContainerFilter(t *testing.T) {
+	t.Parallel()
+
+	ws := new(restful.WebService)
+	ws.Route(ws.GET("/embed").
+		Do(RouteOverride(func(c *Config) { c.FrameOptions = "" })).
+		To(func(req *restful.Request, resp *restful.Response) {}))
+
+	container := restful.NewContainer()
+	container.Filter(Filter())
+	container.Add(ws)
+
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/embed", nil))
+
+	// the route is selected before the Container filters run
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(HeaderFrameOptions))
+	assert.Equal(t, DefaultContentSecurityPolicy, recorder.Header().Get(HeaderContentSecurityPolicy))
+}